@@ -0,0 +1,80 @@
+// 图片比较工具：输出两张渲染结果的MSE/RMSE/PSNR/SSIM，可选写出误差热力图，低于阈值时退出码为1
+//
+//	go run ./cmd/imgdiff -heatmap diff.png reference.png test.png
+//	go run ./cmd/imgdiff -min-psnr 30 -min-ssim 0.9 reference.ppm test.ppm
+package main
+
+import (
+	"RayTracingInOneWeekend/imagediff"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	flags := flag.NewFlagSet("imgdiff", flag.ExitOnError)
+	heatmap := flags.String("heatmap", "", "write a false-colour error heatmap to this path")
+	scale := flags.Float64("scale", 0, "error that maps to the top of the heatmap colour scale, 0 uses the largest error in the image")
+	minPSNR := flags.Float64("min-psnr", 0, "exit with status 1 when PSNR (dB) is below this value")
+	minSSIM := flags.Float64("min-ssim", 0, "exit with status 1 when SSIM is below this value")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: imgdiff [flags] reference test\n\nimages can be PPM, PFM, PNG or JPEG")
+		flags.PrintDefaults()
+	}
+	flags.Parse(os.Args[1:])
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	ok, err := run(flags.Arg(0), flags.Arg(1), *heatmap, *scale, *minPSNR, *minSSIM)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "imgdiff:", err)
+		os.Exit(2)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func run(referencePath, testPath, heatmapPath string, scale, minPSNR, minSSIM float64) (bool, error) {
+	if imagediff.IsLinear(referencePath) != imagediff.IsLinear(testPath) {
+		return false, fmt.Errorf("cannot compare linear PFM values with a display-encoded image")
+	}
+	reference, err := imagediff.Load(referencePath)
+	if err != nil {
+		return false, err
+	}
+	test, err := imagediff.Load(testPath)
+	if err != nil {
+		return false, err
+	}
+	metrics, err := imagediff.Compare(reference, test)
+	if err != nil {
+		return false, err
+	}
+	fmt.Printf("size:  %dx%d\n", reference.Width, reference.Height)
+	fmt.Printf("MSE:   %.6g\n", metrics.MSE)
+	fmt.Printf("RMSE:  %.6g\n", metrics.RMSE)
+	fmt.Printf("PSNR:  %.2f dB\n", metrics.PSNR)
+	fmt.Printf("SSIM:  %.4f\n", metrics.SSIM)
+	if heatmapPath != "" {
+		heatmap, usedScale, err := imagediff.Heatmap(reference, test, scale)
+		if err != nil {
+			return false, err
+		}
+		if err := heatmap.Save(heatmapPath); err != nil {
+			return false, err
+		}
+		fmt.Printf("heatmap: %s (scale %.4g)\n", heatmapPath, usedScale)
+	}
+	ok := true
+	if metrics.PSNR < minPSNR {
+		fmt.Printf("PSNR %.2f dB is below %v dB\n", metrics.PSNR, minPSNR)
+		ok = false
+	}
+	if metrics.SSIM < minSSIM {
+		fmt.Printf("SSIM %.4f is below %v\n", metrics.SSIM, minSSIM)
+		ok = false
+	}
+	return ok, nil
+}
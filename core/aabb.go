@@ -1,5 +1,10 @@
 package core
 
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+)
+
 type AABB struct {
 	X, Y, Z Interval
 }
@@ -49,45 +54,53 @@ func (aabb AABB) AxisInterval(n int) *Interval {
 	}
 }
 
+// Hit 射线（任意t）是否与包围盒相交
 func (aabb AABB) Hit(r *Ray) bool {
-	var rayInterval Interval
-	var rayOrigin = r.Origin
-	var rayDirection = r.Direction
-	for i := 0; i < 3; i++ {
-		// 获取轴
-		axisInterval := aabb.AxisInterval(i)
-		adinv, t0, t1 := 0.0, 0.0, 0.0
-		switch i {
-		case 0:
-			adinv = 1.0 / rayDirection.X
-			t0 = (axisInterval.Min - rayOrigin.X) * adinv
-			t1 = (axisInterval.Max - rayOrigin.X) * adinv
+	return aabb.HitInterval(r, NewUniverseInterval())
+}
 
-		case 1:
-			adinv = 1.0 / rayDirection.Y
-			t0 = (axisInterval.Min - rayOrigin.Y) * adinv
-			t1 = (axisInterval.Max - rayOrigin.Y) * adinv
-		default:
-			adinv = 1.0 / rayDirection.Z
-			t0 = (axisInterval.Min - rayOrigin.Z) * adinv
-			t1 = (axisInterval.Max - rayOrigin.Z) * adinv
-		}
-		if t0 < t1 {
-			if t0 > rayInterval.Min {
-				rayInterval.Min = t0
-			}
-			if t1 < rayInterval.Max {
-				rayInterval.Max = t1
-			}
-		} else {
-			if t0 > rayInterval.Max {
-				rayInterval.Max = t0
-			}
-			if t1 > rayInterval.Min {
-				rayInterval.Min = t1
+// HitInterval slab方法：依次与三个轴的平板求交，收窄射线参数区间，区间为空则未击中
+func (aabb AABB) HitInterval(r *Ray, rayT Interval) bool {
+	_, hit := aabb.Clip(r, rayT)
+	return hit
+}
+
+// Clip 返回射线参数区间rayT中位于包围盒内的部分
+func (aabb AABB) Clip(r *Ray, rayT Interval) (Interval, bool) {
+	origin := [3]float64{r.Origin.X, r.Origin.Y, r.Origin.Z}
+	direction := [3]float64{r.Direction.X, r.Direction.Y, r.Direction.Z}
+	for axis := 0; axis < 3; axis++ {
+		axisInterval := aabb.AxisInterval(axis)
+		if direction[axis] == 0 {
+			// 平行于平板，起点必须在平板内
+			if origin[axis] < axisInterval.Min || origin[axis] > axisInterval.Max {
+				return rayT, false
 			}
+			continue
+		}
+		adinv := 1.0 / direction[axis]
+		t0 := (axisInterval.Min - origin[axis]) * adinv
+		t1 := (axisInterval.Max - origin[axis]) * adinv
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > rayT.Min {
+			rayT.Min = t0
+		}
+		if t1 < rayT.Max {
+			rayT.Max = t1
 		}
-		if rayInterval.Max < rayInterval.Min {
+		if rayT.Max <= rayT.Min {
+			return rayT, false
+		}
+	}
+	return rayT, true
+}
+
+// IsBounded 三个轴的区间是否都有限，无限平面等无界物体的包围盒返回false
+func (aabb AABB) IsBounded() bool {
+	for _, i := range [3]Interval{aabb.X, aabb.Y, aabb.Z} {
+		if math.Abs(i.Min) >= utils.Infinity || math.Abs(i.Max) >= utils.Infinity {
 			return false
 		}
 	}
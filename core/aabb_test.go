@@ -0,0 +1,27 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAABBHitInterval(t *testing.T) {
+	box := NewAABBFromPoints(Point{X: -1, Y: -1, Z: 4}, Point{X: 1, Y: 1, Z: 6})
+	tests := []struct {
+		ray  Ray
+		rayT Interval
+		want bool
+	}{
+		{NewRay(Point{}, Vec3{Z: 1}), NewInterval(0, math.Inf(1)), true},
+		{NewRay(Point{}, Vec3{Z: -1}), NewInterval(0, math.Inf(1)), false},
+		{NewRay(Point{}, Vec3{Z: -1}), NewUniverseInterval(), true},
+		{NewRay(Point{}, Vec3{Z: 1}), NewInterval(0, 3), false},
+		{NewRay(Point{X: 2}, Vec3{Z: 1}), NewInterval(0, math.Inf(1)), false},
+		{NewRay(Point{X: -3, Z: 5}, Vec3{X: 1, Y: 0.1}), NewInterval(0, math.Inf(1)), true},
+	}
+	for i, test := range tests {
+		if got := box.HitInterval(&test.ray, test.rayT); got != test.want {
+			t.Errorf("%d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+关键帧动画：
+AnimationTrack 保存按时间排序的关键帧，每个关键帧包含轴心点的位置、欧拉角旋转（度）和缩放。
+位置支持线性插值和Bezier插值（控制点由相邻关键帧按Catmull-Rom方式自动推出，曲线经过每个关键帧且切线连续），
+旋转和缩放始终线性插值。时间早于第一帧或晚于最后一帧时保持在首/尾帧。
+
+Animated 将任意物体挂到轨道上：物体按原始坐标建模，轴心点默认为其包围盒中心，
+t时刻的世界坐标为 Position(t) + R(t)*S(t)*(p - Pivot)。求交时把射线逆变换到物体的原始坐标系中。
+*/
+
+type Interpolation string
+
+const (
+	LinearInterpolation Interpolation = "linear"
+	BezierInterpolation Interpolation = "bezier"
+)
+
+// Keyframe 关键帧
+type Keyframe struct {
+	Time     float64
+	Position Point // 轴心点所在位置
+	Rotation Vec3  // 欧拉角（度）
+	Scale    Vec3  // 缩放，零值视为{1,1,1}
+}
+
+// AnimationTrack 关键帧轨道
+type AnimationTrack struct {
+	Keyframes     []Keyframe
+	Interpolation Interpolation
+}
+
+func NewAnimationTrack(interpolation Interpolation, keyframes ...Keyframe) (*AnimationTrack, error) {
+	switch interpolation {
+	case LinearInterpolation, BezierInterpolation:
+	default:
+		return nil, fmt.Errorf("unknown interpolation %q", interpolation)
+	}
+	if len(keyframes) == 0 {
+		return nil, fmt.Errorf("animation track needs at least one keyframe")
+	}
+	frames := make([]Keyframe, len(keyframes))
+	copy(frames, keyframes)
+	sort.SliceStable(frames, func(i, j int) bool {
+		return frames[i].Time < frames[j].Time
+	})
+	for i := range frames {
+		if frames[i].Scale == (Vec3{}) {
+			frames[i].Scale = Vec3{1, 1, 1}
+		}
+		if s := frames[i].Scale; s.X == 0 || s.Y == 0 || s.Z == 0 {
+			return nil, fmt.Errorf("keyframe at time %v has a zero scale component: %v", frames[i].Time, s)
+		}
+		if i > 0 && frames[i].Time == frames[i-1].Time {
+			return nil, fmt.Errorf("two keyframes at time %v", frames[i].Time)
+		}
+	}
+	return &AnimationTrack{Keyframes: frames, Interpolation: interpolation}, nil
+}
+
+// segment 返回t所在的关键帧区间[k,k+1]及区间内的归一化参数s
+func (a *AnimationTrack) segment(t float64) (k int, s float64) {
+	frames := a.Keyframes
+	if len(frames) == 1 || t <= frames[0].Time {
+		return 0, 0
+	}
+	last := len(frames) - 1
+	if t >= frames[last].Time {
+		return last - 1, 1
+	}
+	k = sort.Search(len(frames), func(i int) bool { return frames[i].Time > t }) - 1
+	return k, (t - frames[k].Time) / (frames[k+1].Time - frames[k].Time)
+}
+
+func lerpVec3(a, b Vec3, s float64) Vec3 {
+	return a.MultiplicationNum(1 - s).Add(b.MultiplicationNum(s))
+}
+
+// PositionAt t时刻轴心点的位置
+func (a *AnimationTrack) PositionAt(t float64) Point {
+	frames := a.Keyframes
+	if len(frames) == 1 {
+		return frames[0].Position
+	}
+	k, s := a.segment(t)
+	p1, p2 := Vec3(frames[k].Position), Vec3(frames[k+1].Position)
+	if a.Interpolation != BezierInterpolation {
+		return Point(lerpVec3(p1, p2, s))
+	}
+	// 端点处复制首尾关键帧作为虚拟邻居
+	p0, p3 := p1, p2
+	if k > 0 {
+		p0 = Vec3(frames[k-1].Position)
+	}
+	if k+2 < len(frames) {
+		p3 = Vec3(frames[k+2].Position)
+	}
+	c1 := p1.Add(p2.Sub(p0).Div(6))
+	c2 := p2.Sub(p3.Sub(p1).Div(6))
+	// 三次Bezier
+	u := 1 - s
+	return Point(p1.MultiplicationNum(u * u * u).
+		Add(c1.MultiplicationNum(3 * u * u * s)).
+		Add(c2.MultiplicationNum(3 * u * s * s)).
+		Add(p2.MultiplicationNum(s * s * s)))
+}
+
+// TransformAt t时刻的变换（旋转与缩放绕原点，平移为轴心点位置）
+func (a *AnimationTrack) TransformAt(t float64) Transform {
+	frames := a.Keyframes
+	k, s := a.segment(t)
+	rotation, scale := frames[k].Rotation, frames[k].Scale
+	if len(frames) > 1 {
+		rotation = lerpVec3(frames[k].Rotation, frames[k+1].Rotation, s)
+		scale = lerpVec3(frames[k].Scale, frames[k+1].Scale, s)
+	}
+	return NewTransform(Vec3(a.PositionAt(t)), rotation, scale)
+}
+
+// Animated 沿关键帧轨道运动的物体
+type Animated struct {
+	Item  HittableItemI
+	Track *AnimationTrack
+	Pivot Point // 物体原始坐标中的轴心点
+	AABB  *AABB
+}
+
+// NewAnimated 轴心点取物体包围盒中心
+func NewAnimated(item HittableItemI, track *AnimationTrack) *Animated {
+	box := item.GetBoundingBox()
+	pivot := Point{
+		X: (box.X.Min + box.X.Max) / 2,
+		Y: (box.Y.Min + box.Y.Max) / 2,
+		Z: (box.Z.Min + box.Z.Max) / 2,
+	}
+	return NewAnimatedWithPivot(item, track, pivot)
+}
+
+func NewAnimatedWithPivot(item HittableItemI, track *AnimationTrack, pivot Point) *Animated {
+	a := &Animated{Item: item, Track: track, Pivot: pivot}
+	a.SetBoundingBox(nil)
+	return a
+}
+
+// transformAt 包含轴心点偏移的完整变换
+func (a *Animated) transformAt(t float64) Transform {
+	transform := a.Track.TransformAt(t)
+	// Position + R*S*(p - Pivot) = (Position - R*S*Pivot) + R*S*p
+	transform.Translation = transform.Translation.Sub(transform.Vector(Vec3(a.Pivot)))
+	return transform
+}
+
+func (a *Animated) NowAt(time float64) Point {
+	return a.Track.PositionAt(time)
+}
+
+func (a *Animated) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	return transformHit(a.Item, a.transformAt(ray.TM), ray, rayT)
+}
+
+func (a *Animated) GetMaterial() MaterialI {
+	if holder, ok := a.Item.(MaterialHolderI); ok {
+		return holder.GetMaterial()
+	}
+	return nil
+}
+
+func (a *Animated) Children() []HittableItemI {
+	return []HittableItemI{a.Item}
+}
+
+// SetBoundingBox 传入的包围盒被忽略，始终按轨道重新计算整个运动过程扫过的包围盒：
+// 在每个关键帧区间内密集采样变换后的包围盒，再按相邻采样间顶点的最大位移向外扩展，
+// 以覆盖采样之间旋转产生的弧线和Bezier曲线的偏离
+func (a *Animated) SetBoundingBox(aabb *AABB) {
+	const steps = 32
+	box := a.Item.GetBoundingBox()
+	frames := a.Track.Keyframes
+	times := []float64{frames[0].Time}
+	for k := 0; k+1 < len(frames); k++ {
+		for i := 1; i <= steps; i++ {
+			times = append(times, frames[k].Time+(frames[k+1].Time-frames[k].Time)*float64(i)/steps)
+		}
+	}
+	corners := func(t float64) [8]Point {
+		transform := a.transformAt(t)
+		var result [8]Point
+		n := 0
+		for _, x := range []float64{box.X.Min, box.X.Max} {
+			for _, y := range []float64{box.Y.Min, box.Y.Max} {
+				for _, z := range []float64{box.Z.Min, box.Z.Max} {
+					result[n] = transform.Point(Point{x, y, z})
+					n++
+				}
+			}
+		}
+		return result
+	}
+	swept := NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval())
+	padding := 0.0
+	previous := corners(times[0])
+	for i, t := range times {
+		current := corners(t)
+		for n, p := range current {
+			swept = NewAABBFromAABB(swept, NewAABBFromPoints(p, p))
+			if i > 0 {
+				padding = math.Max(padding, Vec3(p).Sub(Vec3(previous[n])).Length())
+			}
+		}
+		previous = current
+	}
+	a.AABB = NewAABB(*swept.X.Expand(padding), *swept.Y.Expand(padding), *swept.Z.Expand(padding))
+}
+
+func (a *Animated) GetBoundingBox() *AABB {
+	return a.AABB
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSphereUniformLinearMovementReachesEnd(t *testing.T) {
+	sphere := NewSphere(Point{1, 0, 0}, 0.5)
+	sphere.SetUniformLinearMovement(Point{1, 2, 0})
+	if p := sphere.NowAt(1); Vec3(p).Sub(Vec3{1, 2, 0}).Length() > 1e-12 {
+		t.Errorf("NowAt(1) = %v, want the end point", p)
+	}
+	if p := sphere.NowAt(0.5); Vec3(p).Sub(Vec3{1, 1, 0}).Length() > 1e-12 {
+		t.Errorf("NowAt(0.5) = %v", p)
+	}
+	if box := sphere.GetBoundingBox(); box.Y.Min != -0.5 || box.Y.Max != 2.5 {
+		t.Errorf("bounding box %v does not cover the motion", box.Y)
+	}
+}
+
+func TestAnimationTrackInterpolation(t *testing.T) {
+	frames := []Keyframe{
+		{Time: 0, Position: Point{0, 0, 0}},
+		{Time: 1, Position: Point{1, 0, 0}, Rotation: Vec3{Y: 90}, Scale: Vec3{2, 2, 2}},
+		{Time: 2, Position: Point{1, 1, 0}},
+	}
+	for _, interpolation := range []Interpolation{LinearInterpolation, BezierInterpolation} {
+		track, err := NewAnimationTrack(interpolation, frames[2], frames[0], frames[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, frame := range frames {
+			if p := track.PositionAt(frame.Time); Vec3(p).Sub(Vec3(frame.Position)).Length() > 1e-12 {
+				t.Errorf("%s: position at key %v = %v", interpolation, frame.Time, p)
+			}
+		}
+		if p := track.PositionAt(-1); p != frames[0].Position {
+			t.Errorf("%s: position before first key = %v", interpolation, p)
+		}
+		transform := track.TransformAt(0.5)
+		if s := transform.Scale; math.Abs(s.X-1.5) > 1e-12 {
+			t.Errorf("%s: scale at 0.5 = %v", interpolation, s)
+		}
+	}
+	linear, _ := NewAnimationTrack(LinearInterpolation, frames...)
+	if p := linear.PositionAt(0.5); Vec3(p).Sub(Vec3{0.5, 0, 0}).Length() > 1e-12 {
+		t.Errorf("linear midpoint = %v", p)
+	}
+	if _, err := NewAnimationTrack(LinearInterpolation, Keyframe{Scale: Vec3{1, 0, 1}}); err == nil {
+		t.Error("expected error for zero scale")
+	}
+}
+
+func TestAnimatedHitAndSweptBounds(t *testing.T) {
+	// 一个中心偏离轴心点的小球绕Y轴公转并上升
+	sphere := NewSphere(Point{2, 0, 0}, 0.25)
+	track, err := NewAnimationTrack(BezierInterpolation,
+		Keyframe{Time: 0, Position: Point{0, 0, 0}},
+		Keyframe{Time: 0.5, Position: Point{0, 1, 0}, Rotation: Vec3{Y: 180}},
+		Keyframe{Time: 1, Position: Point{0, 2, 0}, Rotation: Vec3{Y: 360}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	animated := NewAnimatedWithPivot(sphere, track, Point{})
+	box := animated.GetBoundingBox()
+	for i := 0; i <= 1000; i++ {
+		tm := float64(i) / 1000
+		transform := animated.transformAt(tm)
+		center := transform.Point(sphere.Center)
+		for _, axis := range []int{0, 1, 2} {
+			v := []float64{center.X, center.Y, center.Z}[axis]
+			if r := box.AxisInterval(axis); v-0.25 < r.Min || v+0.25 > r.Max {
+				t.Fatalf("sphere at t=%v (%v) escapes the swept box %v", tm, center, *box)
+			}
+		}
+	}
+	// t=0.5时小球转到(-2,1,0)
+	ray := NewRayWithTime(Point{-2, 1, 5}, Vec3{0, 0, -1}, 0.5)
+	hit, record := animated.Hittable(ray, NewInterval(0.001, math.Inf(1)))
+	if !hit || math.Abs(record.Time-4.75) > 1e-6 || record.Normal.Sub(Vec3{0, 0, 1}).Length() > 1e-6 {
+		t.Fatalf("hit = %v, record = %+v", hit, record)
+	}
+	ray.TM = 0
+	if hit, _ := animated.Hittable(ray, NewInterval(0.001, math.Inf(1))); hit {
+		t.Error("ray hit the sphere at t=0 where it is elsewhere")
+	}
+}
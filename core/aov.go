@@ -0,0 +1,265 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+/*
+AOV（Arbitrary Output Variables）辅助缓冲：
+在相机射线的首次击中点记录反照率、着色法线、世界坐标、深度以及物体/材质编号，
+用于合成和降噪。反照率、法线、位置和深度取像素内所有采样的平均值，编号取第一个击中的采样。
+未击中时反照率记为背景色，编号为0。
+*/
+
+// AlbedoMaterialI 可提供反照率的材质
+type AlbedoMaterialI interface {
+	AlbedoAt(h HitRecord) Color
+}
+
+// MaterialHolderI 持有单一材质的物体，用于分配材质编号
+type MaterialHolderI interface {
+	GetMaterial() MaterialI
+}
+
+// ContainerI 由其他物体组合而成的物体（BVH节点、实例、CSG等），用于遍历场景中的全部材质
+type ContainerI interface {
+	Children() []HittableItemI
+}
+
+// AOVSample 单个采样在首次击中点的辅助信息
+type AOVSample struct {
+	Hit        bool
+	Albedo     Color
+	Normal     Vec3
+	Position   Point
+	Depth      float64 // 沿射线方向到击中点的距离
+	ObjectID   int     // 从1开始，0表示未击中
+	MaterialID int     // 从1开始，0表示未击中或未知材质
+}
+
+// AOVBuffer 辅助帧缓冲，按行存储
+type AOVBuffer struct {
+	Width, Height int
+	Albedo        []Color
+	Normal        []Vec3
+	Position      []Point
+	Depth         []float64 // 未击中的像素为+Inf
+	ObjectID      []int
+	MaterialID    []int
+	samples       []int // 每像素采样数
+	hits          []int // 每像素击中数
+	resolved      bool
+}
+
+func NewAOVBuffer(width, height int) *AOVBuffer {
+	n := width * height
+	return &AOVBuffer{
+		Width:      width,
+		Height:     height,
+		Albedo:     make([]Color, n),
+		Normal:     make([]Vec3, n),
+		Position:   make([]Point, n),
+		Depth:      make([]float64, n),
+		ObjectID:   make([]int, n),
+		MaterialID: make([]int, n),
+		samples:    make([]int, n),
+		hits:       make([]int, n),
+	}
+}
+
+// Add 累积像素(i,j)的一个采样
+func (a *AOVBuffer) Add(i, j int, s AOVSample) {
+	index := j*a.Width + i
+	a.samples[index]++
+	a.Albedo[index] = Color(Vec3(a.Albedo[index]).Add(Vec3(s.Albedo)))
+	if !s.Hit {
+		return
+	}
+	if a.hits[index] == 0 {
+		a.ObjectID[index] = s.ObjectID
+		a.MaterialID[index] = s.MaterialID
+	}
+	a.hits[index]++
+	a.Normal[index] = a.Normal[index].Add(s.Normal)
+	a.Position[index] = Point(Vec3(a.Position[index]).Add(Vec3(s.Position)))
+	a.Depth[index] += s.Depth
+}
+
+// Resolve 将累积值转换为平均值，只会执行一次
+func (a *AOVBuffer) Resolve() {
+	if a.resolved {
+		return
+	}
+	a.resolved = true
+	for index := range a.samples {
+		if n := a.samples[index]; n > 0 {
+			a.Albedo[index] = Color(Vec3(a.Albedo[index]).Div(float64(n)))
+		}
+		n := a.hits[index]
+		if n == 0 {
+			a.Depth[index] = math.Inf(1)
+			continue
+		}
+		if l := a.Normal[index].Length(); l > 0 {
+			a.Normal[index] = a.Normal[index].Div(l)
+		}
+		a.Position[index] = Point(Vec3(a.Position[index]).Div(float64(n)))
+		a.Depth[index] /= float64(n)
+	}
+}
+
+// Save 将各缓冲可视化后按path的扩展名保存，out.png 保存为 out_albedo/normal/position/depth/object/material.png
+func (a *AOVBuffer) Save(path string) error {
+	a.Resolve()
+	n := a.Width * a.Height
+	albedo := make([]utils.Pixel, n)
+	normal := make([]utils.Pixel, n)
+	position := make([]utils.Pixel, n)
+	depth := make([]utils.Pixel, n)
+	object := make([]utils.Pixel, n)
+	material := make([]utils.Pixel, n)
+
+	// 位置和深度按场景范围归一化
+	maxDepth := 0.0
+	bounds := NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval())
+	for index := range a.Depth {
+		if a.hits[index] == 0 {
+			continue
+		}
+		maxDepth = math.Max(maxDepth, a.Depth[index])
+		bounds = NewAABBFromAABB(bounds, NewAABBFromPoints(a.Position[index], a.Position[index]))
+	}
+	for index := 0; index < n; index++ {
+		c := a.Albedo[index]
+		albedo[index] = c.Color2Pixel()
+		object[index] = idColor(a.ObjectID[index])
+		material[index] = idColor(a.MaterialID[index])
+		if a.hits[index] == 0 {
+			continue
+		}
+		nrm := a.Normal[index]
+		normal[index] = linearPixel(Color{(nrm.X + 1) / 2, (nrm.Y + 1) / 2, (nrm.Z + 1) / 2})
+		p := a.Position[index]
+		position[index] = linearPixel(Color{
+			normalizeIn(p.X, bounds.X),
+			normalizeIn(p.Y, bounds.Y),
+			normalizeIn(p.Z, bounds.Z),
+		})
+		d := 1.0
+		if maxDepth > 0 {
+			d = 1 - a.Depth[index]/maxDepth // 近处亮
+		}
+		depth[index] = linearPixel(Color{d, d, d})
+	}
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(path, ext)
+	for _, buffer := range []struct {
+		suffix string
+		pixels []utils.Pixel
+	}{
+		{"_albedo", albedo},
+		{"_normal", normal},
+		{"_position", position},
+		{"_depth", depth},
+		{"_object", object},
+		{"_material", material},
+	} {
+		if err := utils.SaveImage(name+buffer.suffix+ext, a.Width, a.Height, buffer.pixels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linearPixel 不做伽马校正直接量化，用于数据类缓冲
+func linearPixel(c Color) utils.Pixel {
+	var interval = Interval{0, 1.0}
+	return utils.Pixel{
+		R: int(interval.Clamp(c.X) * 255),
+		G: int(interval.Clamp(c.Y) * 255),
+		B: int(interval.Clamp(c.Z) * 255),
+	}
+}
+
+func normalizeIn(x float64, i Interval) float64 {
+	if i.Size() <= 0 {
+		return 0.5
+	}
+	return (x - i.Min) / i.Size()
+}
+
+// idColor 将编号散列成易于区分的颜色，0为黑色
+func idColor(id int) utils.Pixel {
+	if id == 0 {
+		return utils.Pixel{}
+	}
+	h := uint32(id) * 2654435761
+	return utils.Pixel{R: int(h>>24) & 0xff, G: int(h>>16) & 0xff, B: int(h>>8) & 0xff}
+}
+
+// aovIDs 渲染开始前为场景中的物体和材质分配稳定的编号
+type aovIDs struct {
+	objects   map[HittableItemI]int
+	materials map[MaterialI]int
+}
+
+func newAOVIDs(items []HittableItemI) *aovIDs {
+	ids := &aovIDs{
+		objects:   make(map[HittableItemI]int, len(items)),
+		materials: make(map[MaterialI]int),
+	}
+	for _, item := range items {
+		ids.objects[item] = len(ids.objects) + 1
+	}
+	for _, item := range items {
+		ids.collect(item)
+	}
+	return ids
+}
+
+// collect 深度优先遍历物体及其子物体并登记材质。
+// 嵌套BVH的叶子会作为击中物体写入击中记录，因此也分配物体编号
+func (ids *aovIDs) collect(item HittableItemI) {
+	if holder, ok := item.(MaterialHolderI); ok && holder.GetMaterial() != nil {
+		if _, exist := ids.materials[holder.GetMaterial()]; !exist {
+			ids.materials[holder.GetMaterial()] = len(ids.materials) + 1
+		}
+	}
+	container, ok := item.(ContainerI)
+	if !ok {
+		return
+	}
+	_, isNode := item.(*BVHNode)
+	for _, child := range container.Children() {
+		if _, childIsNode := child.(*BVHNode); isNode && !childIsNode {
+			if _, exist := ids.objects[child]; !exist {
+				ids.objects[child] = len(ids.objects) + 1
+			}
+		}
+		ids.collect(child)
+	}
+}
+
+// sample 根据击中记录生成辅助采样
+func (ids *aovIDs) sample(h HitRecord) *AOVSample {
+	s := &AOVSample{
+		Hit:      true,
+		Normal:   h.Normal,
+		Position: h.HitPoint,
+		Depth:    h.Time,
+		Albedo:   Color{1, 1, 1},
+	}
+	if m, ok := h.Material.(AlbedoMaterialI); ok {
+		s.Albedo = m.AlbedoAt(h)
+	}
+	if h.Object != nil {
+		s.ObjectID = ids.objects[h.Object]
+	}
+	if h.Material != nil {
+		s.MaterialID = ids.materials[h.Material]
+	}
+	return s
+}
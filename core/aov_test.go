@@ -0,0 +1,75 @@
+package core
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAOV(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 1, 90, 16, 4, 4, true, 0, 1)
+	material := LambertianReflectionMaterial{Albedo: Color{0.2, 0.4, 0.6}}
+	camera.Add(NewSphere(Point{0, 0, -2}, 0.5).WithMaterial(material))
+	camera.EnabledAOV(true)
+	dir := t.TempDir()
+	if _, err := camera.Render(filepath.Join(dir, "aov.png")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"aov.png", "aov_albedo.png", "aov_normal.png", "aov_material.png"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	aov := camera.AOV()
+	center := 8*aov.Width + 8
+	if aov.ObjectID[center] != 1 || aov.MaterialID[center] != 1 {
+		t.Fatalf("center ids = %d/%d, want 1/1", aov.ObjectID[center], aov.MaterialID[center])
+	}
+	if a := aov.Albedo[center]; math.Abs(a.Y-0.4) > 1e-9 {
+		t.Errorf("center albedo = %v", a)
+	}
+	if n := aov.Normal[center]; n.Z < 0.9 {
+		t.Errorf("center normal = %v, want facing the camera", n)
+	}
+	if d := aov.Depth[center]; d < 1.4 || d > 1.6 {
+		t.Errorf("center depth = %v, want about 1.5", d)
+	}
+	if aov.ObjectID[0] != 0 || !math.IsInf(aov.Depth[0], 1) {
+		t.Errorf("corner should be background, got id %d depth %v", aov.ObjectID[0], aov.Depth[0])
+	}
+}
+
+// BVH节点、实例和CSG内部物体的材质也要分配编号，否则击中时材质编号为0
+func TestAOVNestedMaterials(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 1, 90, 16, 2, 2, true, 0, 1)
+	lambertian := func(g float64) MaterialI { return LambertianReflectionMaterial{Albedo: Color{0.5, g, 0.5}} }
+	group := NewBVHNode([]HittableItemI{
+		NewSphere(Point{-0.6, 0.6, -2}, 0.3).WithMaterial(lambertian(0.1)),
+		NewSphere(Point{0.6, 0.6, -2}, 0.3).WithMaterial(lambertian(0.2)),
+	})
+	instance := NewInstance(NewSphere(Point{}, 0.3).WithMaterial(lambertian(0.3)), NewTransform(Vec3{-0.6, -0.6, -2}, Vec3{}, Vec3{1, 1, 1}))
+	carved := NewDifference(
+		NewSphere(Point{0.6, -0.6, -2}, 0.3).WithMaterial(lambertian(0.4)),
+		NewSphere(Point{0.6, -0.6, -1.7}, 0.1).WithMaterial(lambertian(0.5)),
+	)
+	camera.Add(group, instance, carved)
+	camera.EnabledAOV(true)
+	camera.RenderToColors()
+
+	aov := camera.AOV()
+	materials := map[int]bool{}
+	for index := range aov.MaterialID {
+		if aov.hits[index] == 0 {
+			continue
+		}
+		if aov.ObjectID[index] == 0 || aov.MaterialID[index] == 0 {
+			t.Fatalf("pixel %d: object %d material %d", index, aov.ObjectID[index], aov.MaterialID[index])
+		}
+		materials[aov.MaterialID[index]] = true
+	}
+	if len(materials) < 4 {
+		t.Errorf("saw %d distinct materials, want at least 4", len(materials))
+	}
+}
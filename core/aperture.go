@@ -0,0 +1,148 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+/*
+光圈形状决定了焦外高光（散景）的形状：
+景深采样时在光圈上随机取一点作为光线起点，光圈的形状会原样投射到失焦的亮点上。
+采样点位于[-1,1]x[-1,1]内，再按散焦盘半径缩放到相机的u、v方向上。
+*/
+
+// ApertureI 光圈形状接口
+type ApertureI interface {
+	Sample(rng *rand.Rand) Vec3 // 光圈内均匀分布的随机点，Z为0；rng为nil时使用全局随机源
+}
+
+// CircularAperture 圆形光圈
+type CircularAperture struct{}
+
+func (CircularAperture) Sample(rng *rand.Rand) Vec3 {
+	return RandomInUnitDisk(rng)
+}
+
+// PolygonAperture N片光圈叶片形成的正多边形，顶点位于单位圆上
+type PolygonAperture struct {
+	Blades   int     // 叶片数（边数），至少为3
+	Rotation float64 // 旋转角度（度）
+}
+
+func NewPolygonAperture(blades int, rotation float64) (*PolygonAperture, error) {
+	if blades < 3 {
+		return nil, fmt.Errorf("polygon aperture needs at least 3 blades, got %d", blades)
+	}
+	return &PolygonAperture{Blades: blades, Rotation: rotation}, nil
+}
+
+func (p PolygonAperture) vertex(k int) Vec3 {
+	angle := utils.Degrees2Radians(p.Rotation) + 2*math.Pi*float64(k)/float64(p.Blades)
+	return Vec3{X: math.Cos(angle), Y: math.Sin(angle)}
+}
+
+// Sample 多边形由中心与相邻顶点组成的等面积三角形构成，先选三角形再在三角形内均匀采样
+func (p PolygonAperture) Sample(rng *rand.Rand) Vec3 {
+	k := int(utils.RandomFrom(rng) * float64(p.Blades))
+	if k >= p.Blades {
+		k = p.Blades - 1
+	}
+	a, b := utils.RandomFrom(rng), utils.RandomFrom(rng)
+	if a+b > 1 {
+		a, b = 1-a, 1-b
+	}
+	return p.vertex(k).MultiplicationNum(a).Add(p.vertex(k + 1).MultiplicationNum(b))
+}
+
+// ImageAperture 由灰度图定义的光圈，亮度越高的位置被采样的概率越大
+type ImageAperture struct {
+	Width, Height int
+	Path          string    // 从图片文件加载时的路径，写入场景文件时引用该文件
+	cdf           []float64 // 像素亮度的累积分布
+}
+
+// NewImageAperture weights为按行存储的灰度值，图像铺满[-1,1]x[-1,1]
+func NewImageAperture(width, height int, weights []float64) (*ImageAperture, error) {
+	if width <= 0 || height <= 0 || len(weights) != width*height {
+		return nil, fmt.Errorf("aperture image is %dx%d but has %d weights", width, height, len(weights))
+	}
+	cdf := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		if w < 0 {
+			return nil, fmt.Errorf("aperture weight %d is negative: %v", i, w)
+		}
+		total += w
+		cdf[i] = total
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("aperture image is completely black")
+	}
+	for i := range cdf {
+		cdf[i] /= total
+	}
+	return &ImageAperture{Width: width, Height: height, cdf: cdf}, nil
+}
+
+// NewImageApertureFromFile 读取PNG、JPEG或PPM灰度图作为光圈
+func NewImageApertureFromFile(path string) (*ImageAperture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	aperture, err := NewImageApertureFromImage(img)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	aperture.Path = path
+	return aperture, nil
+}
+
+// NewImageApertureFromImage 像素亮度（线性，不做伽马校正）作为采样权重，彩色图片先转换为灰度
+func NewImageApertureFromImage(img image.Image) (*ImageAperture, error) {
+	bounds := img.Bounds()
+	weights := make([]float64, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			weights = append(weights, float64(gray.Y)/0xffff)
+		}
+	}
+	return NewImageAperture(bounds.Dx(), bounds.Dy(), weights)
+}
+
+func (a ImageAperture) Sample(rng *rand.Rand) Vec3 {
+	index := sort.SearchFloat64s(a.cdf, utils.RandomFrom(rng))
+	if index >= len(a.cdf) {
+		index = len(a.cdf) - 1
+	}
+	px := float64(index%a.Width) + utils.RandomFrom(rng)
+	py := float64(index/a.Width) + utils.RandomFrom(rng)
+	return Vec3{
+		X: px/float64(a.Width)*2 - 1,
+		Y: 1 - py/float64(a.Height)*2,
+	}
+}
+
+// insideVignette 光学渐晕（猫眼效果）：离图像中心越远，镜筒对光圈的遮挡越多，
+// 光圈采样点需同时落在沿图像位置方向偏移的第二个单位圆内，否则被遮挡
+func (c *Camera) insideVignette(p Vec3, x, y float64) bool {
+	halfW, halfH := float64(c.ImageWidth)/2, float64(c.ImageHeight)/2
+	halfDiagonal := math.Sqrt(halfW*halfW + halfH*halfH)
+	// 图像坐标y向下，光圈坐标y向上
+	sx := (x + 0.5 - halfW) / halfDiagonal
+	sy := -(y + 0.5 - halfH) / halfDiagonal
+	shift := Vec3{X: sx, Y: sy}.MultiplicationNum(c.OpticalVignetting)
+	return p.Sub(shift).LengthSquared() <= 1
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPolygonApertureStaysInside(t *testing.T) {
+	aperture, err := NewPolygonAperture(6, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 10000; n++ {
+		p := aperture.Sample(rng)
+		for k := 0; k < aperture.Blades; k++ {
+			a, b := aperture.vertex(k), aperture.vertex(k+1)
+			// 逆时针排列的顶点，内部点位于每条边的左侧
+			if b.Sub(a).Cross(p.Sub(a)).Z < -1e-12 {
+				t.Fatalf("sample %v outside edge %d", p, k)
+			}
+		}
+	}
+	if _, err := NewPolygonAperture(2, 0); err == nil {
+		t.Error("expected error for 2 blades")
+	}
+}
+
+func TestImageApertureFollowsWeights(t *testing.T) {
+	// 2x2 图像只有右上角亮
+	aperture, err := NewImageAperture(2, 2, []float64{0, 1, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 1000; n++ {
+		if p := aperture.Sample(rng); p.X < 0 || p.X > 1 || p.Y < 0 || p.Y > 1 {
+			t.Fatalf("sample %v outside the bright quadrant", p)
+		}
+	}
+	if _, err := NewImageAperture(2, 2, []float64{0, 0, 0, 0}); err == nil {
+		t.Error("expected error for black aperture")
+	}
+}
+
+// 从灰度图加载的光圈与直接给出权重相同，写入场景文件时引用图片路径
+func TestImageApertureFromFile(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(1, 0, color.Gray{Y: 255})
+	path := filepath.Join(t.TempDir(), "bokeh.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	aperture, err := NewImageApertureFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := NewImageAperture(2, 2, []float64{0, 1, 0, 0})
+	if !slices.Equal(aperture.cdf, want.cdf) {
+		t.Errorf("cdf = %v, want %v", aperture.cdf, want.cdf)
+	}
+
+	camera := NewCamera(Point{}, Point{Z: 1}, 1, 90, 8, 1, 1, true, 1, 1)
+	camera.SetAperture(aperture, 0)
+	camera.Add(NewSphere(Point{}, 1).WithMaterial(LambertianReflectionMaterial{Albedo: Color{X: 0.5}}))
+	var buf bytes.Buffer
+	if err := WriteScene(&buf, camera, RenderSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"path": `) || strings.Contains(buf.String(), `"weights"`) {
+		t.Errorf("aperture was not written by path:\n%s", buf.String())
+	}
+	reloaded, _, err := LoadScene(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reloaded.Aperture.(*ImageAperture); !ok || got.Path != path || !slices.Equal(got.cdf, want.cdf) {
+		t.Errorf("reloaded aperture %+v", reloaded.Aperture)
+	}
+	if _, err := NewImageApertureFromFile(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestOpticalVignetting(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 1, 60, 100, 1, 4, true, 10, 2)
+	camera.SetAperture(CircularAperture{}, 1)
+	blocked := func(i, j int) int {
+		count := 0
+		for n := 0; n < 2000; n++ {
+			if camera.GetRayWithOffset(i, j, Vec3{X: -0.5, Y: -0.5}) == nil {
+				count++
+			}
+		}
+		return count
+	}
+	// 图像正中心不受遮挡
+	if n := blocked(50, 50); n != 0 {
+		t.Errorf("center pixel blocked %d times", n)
+	}
+	if n := blocked(0, 0); n == 0 {
+		t.Error("corner pixel was never vignetted")
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import "math"
+
+/*
+长方体：由两个相对的顶点确定，六个面都是四边形，法线朝外。
+作为一个整体物体参与求交，包围盒正好是两个顶点张成的范围，旋转等变换通过 Instance 实现。
+*/
+type Box struct {
+	Min, Max Point
+	Material MaterialI
+	AABB     *AABB
+	sides    [6]*Quad
+}
+
+func NewBox(a, b Point) *Box {
+	p0 := Point{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y), Z: math.Min(a.Z, b.Z)}
+	p1 := Point{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y), Z: math.Max(a.Z, b.Z)}
+	dx := Vec3{X: p1.X - p0.X}
+	dy := Vec3{Y: p1.Y - p0.Y}
+	dz := Vec3{Z: p1.Z - p0.Z}
+	box := &Box{
+		Min: p0,
+		Max: p1,
+		sides: [6]*Quad{
+			NewQuad(Point{X: p0.X, Y: p0.Y, Z: p1.Z}, dx, dy),                       // 前
+			NewQuad(Point{X: p1.X, Y: p0.Y, Z: p1.Z}, dz.MultiplicationNum(-1), dy), // 右
+			NewQuad(Point{X: p1.X, Y: p0.Y, Z: p0.Z}, dx.MultiplicationNum(-1), dy), // 后
+			NewQuad(Point{X: p0.X, Y: p0.Y, Z: p0.Z}, dz, dy),                       // 左
+			NewQuad(Point{X: p0.X, Y: p1.Y, Z: p1.Z}, dx, dz.MultiplicationNum(-1)), // 上
+			NewQuad(Point{X: p0.X, Y: p0.Y, Z: p0.Z}, dx, dz),                       // 下
+		},
+	}
+	box.SetBoundingBox(nil)
+	return box
+}
+
+// WithMaterial 六个面共用同一个材质
+func (b *Box) WithMaterial(mat MaterialI) *Box {
+	b.Material = mat
+	for _, side := range b.sides {
+		side.WithMaterial(mat)
+	}
+	return b
+}
+
+func (b *Box) GetMaterial() MaterialI {
+	return b.Material
+}
+
+// SetBoundingBox 传入nil时使用两个顶点张成的范围，厚度为0的轴稍微扩展
+func (b *Box) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		b.AABB = aabb
+		return
+	}
+	b.AABB = padAABB(NewAABBFromPoints(b.Min, b.Max), 1e-4)
+}
+
+func (b *Box) GetBoundingBox() *AABB {
+	return b.AABB
+}
+
+// Hittable 先与包围盒求交，再依次与六个面求交，保留最近的交点
+func (b *Box) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	if !b.AABB.HitInterval(&ray, rayT) {
+		return false, hitRecord
+	}
+	closest := rayT.Max
+	for _, side := range b.sides {
+		if sideHit, record := side.Hittable(ray, NewInterval(rayT.Min, closest)); sideHit {
+			hit = true
+			closest = record.Time
+			hitRecord = record
+		}
+	}
+	return hit, hitRecord
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBox(t *testing.T) {
+	material := LambertianReflectionMaterial{Albedo: Color{X: 0.5}}
+	box := NewBox(Point{X: 1, Y: 2, Z: 3}, Point{X: -1, Y: -2, Z: -3}).WithMaterial(material)
+	if aabb := box.GetBoundingBox(); aabb.X != (Interval{-1, 1}) || aabb.Y != (Interval{-2, 2}) || aabb.Z != (Interval{-3, 3}) {
+		t.Errorf("bounding box %+v", aabb)
+	}
+	tests := []struct {
+		ray    Ray
+		time   float64
+		normal Vec3
+		front  bool
+	}{
+		{NewRay(Point{Z: 10}, Vec3{Z: -1}), 7, Vec3{Z: 1}, true},
+		{NewRay(Point{X: -5, Y: 0.5}, Vec3{X: 1}), 4, Vec3{X: -1}, true},
+		{NewRay(Point{Y: -10}, Vec3{Y: 1}), 8, Vec3{Y: -1}, true},
+		{NewRay(Point{}, Vec3{Y: 1}), 2, Vec3{Y: -1}, false}, // 从内部射出
+	}
+	for _, test := range tests {
+		hit, record := box.Hittable(test.ray, NewInterval(1e-5, math.Inf(1)))
+		if !hit {
+			t.Errorf("%v: expected a hit", test.ray)
+			continue
+		}
+		if math.Abs(record.Time-test.time) > 1e-9 || record.Normal != test.normal || record.FrontFace != test.front {
+			t.Errorf("%v: t=%v normal=%v front=%v", test.ray, record.Time, record.Normal, record.FrontFace)
+		}
+		if record.Material != material {
+			t.Errorf("%v: material %v", test.ray, record.Material)
+		}
+	}
+	if hit, _ := box.Hittable(NewRay(Point{X: 2, Z: 10}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1))); hit {
+		t.Error("ray beside the box hit it")
+	}
+
+	// 绕Y轴旋转45度后包围盒随之变大，仍能被射线击中
+	rotated := NewInstance(NewBox(Point{X: -1, Y: -1, Z: -1}, Point{X: 1, Y: 1, Z: 1}), NewTransform(Vec3{}, Vec3{Y: 45}, Vec3{X: 1, Y: 1, Z: 1}))
+	if size := rotated.GetBoundingBox().X.Size(); math.Abs(size-2*math.Sqrt2) > 1e-6 {
+		t.Errorf("rotated box width %v", size)
+	}
+	hit, record := rotated.Hittable(NewRay(Point{Z: 10}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1)))
+	if !hit || math.Abs(record.Time-(10-math.Sqrt2)) > 1e-6 {
+		t.Errorf("rotated box hit=%v t=%v", hit, record.Time)
+	}
+}
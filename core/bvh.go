@@ -11,7 +11,8 @@ type BVHNode struct {
 }
 
 func (B BVHNode) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
-	if !B.AABB.Hit(&ray) {
+	ray.counters.bvhNodeVisit()
+	if !B.AABB.HitInterval(&ray, rayT) {
 		// 没打到，直接返回
 		return false, HitRecord{}
 	}
@@ -20,11 +21,15 @@ func (B BVHNode) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord
 	maxTime := rayT.Max
 	if hitLeft {
 		maxTime = hitRecordLeft.Time
+		markLeafObject(B.Left, &hitRecordLeft)
 	}
 	hitRight, hitRecordRight := B.Right.Hittable(ray, Interval{
 		Min: rayT.Min,
 		Max: maxTime,
 	})
+	if hitRight {
+		markLeafObject(B.Right, &hitRecordRight)
+	}
 
 	// 都未击中
 	if !hitRight && !hitLeft {
@@ -53,6 +58,17 @@ func (B BVHNode) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord
 	return true, hitRecord
 }
 
+// markLeafObject 叶子节点即场景顶层物体，记录到击中记录中
+func markLeafObject(child HittableItemI, hitRecord *HitRecord) {
+	if _, isNode := child.(*BVHNode); !isNode {
+		hitRecord.Object = child
+	}
+}
+
+func (B BVHNode) Children() []HittableItemI {
+	return []HittableItemI{B.Left, B.Right}
+}
+
 func (B BVHNode) SetBoundingBox(aabb *AABB) {
 	B.AABB = aabb
 }
@@ -61,13 +77,10 @@ func (B BVHNode) GetBoundingBox() *AABB {
 	return B.AABB
 }
 
+// NewBVHNode 原地排序hittableList并构建层次结构，列表不能为空，且只应包含有界物体，无界物体见 Scenes.BuildBVH
 func NewBVHNode(hittableList []HittableItemI) (bvhNode *BVHNode) {
 	bvhNode = new(BVHNode)
-	aabb := &AABB{
-		X: Interval{},
-		Y: Interval{},
-		Z: Interval{},
-	}
+	aabb := NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval())
 	// 最长的轴分割
 	for _, itemI := range hittableList {
 		aabb = NewAABBFromAABB(aabb, itemI.GetBoundingBox())
@@ -2,9 +2,10 @@ package core
 
 import (
 	"RayTracingInOneWeekend/utils"
-	"fmt"
 	"math"
+	"math/rand"
 	"sync"
+	"time"
 )
 
 /*
@@ -21,31 +22,46 @@ import (
 */
 
 type Camera struct {
-	ImageWidth                 int     // 渲染窗口宽
-	ImageHeight                int     // 渲染窗口高
-	SamplesPerPixel            int     // 每像素采样数量
-	MaxDepth                   int     // 光线最大递归深度
-	AspectRatio                float64 // 宽高比
-	ViewportHeight             float64 // 视口高度
-	ViewportWidth              float64 // 视口宽度
-	FocalLength                float64 // 焦距
-	PixelSamplesScale          float64 // 每采样权重
-	VFov                       float64 // 视野
-	DefocusAngle               float64 // 每像素通过的光线变化角度（景深）
-	FocusDist                  float64 // 相机观察点与完美焦点平面之间的距离
-	CameraCenter               Point   // 相机位置
-	LookAt                     Point   // 光线出发点
-	LookFrom                   Point   // 焦点
-	ViewportU                  Vec3    // 视口水平长度向量
-	ViewportV                  Vec3    // 视口垂直长度向量
-	PixelDeltaU                Vec3    // 像素水平间隔
-	PixelDeltaV                Vec3    // 像素垂直间隔
-	ViewportUpperLeft          Vec3    // 视口左上向量
-	Pixel00Local               Vec3    // 视口原点
-	world                      Scenes  // 场景
-	IsAntialiased              bool    // 抗锯齿
-	u, v, w, vup               Vec3    // Camera frame basis vectors and Camera-relative "up" direction
+	ImageWidth                 int                    // 渲染窗口宽
+	ImageHeight                int                    // 渲染窗口高
+	SamplesPerPixel            int                    // 每像素采样数量
+	MaxDepth                   int                    // 光线最大递归深度
+	AspectRatio                float64                // 宽高比
+	ViewportHeight             float64                // 视口高度
+	ViewportWidth              float64                // 视口宽度
+	FocalLength                float64                // 焦距
+	PixelSamplesScale          float64                // 每采样权重
+	VFov                       float64                // 视野
+	Model                      CameraModelI           // 投影模型
+	DefocusAngle               float64                // 每像素通过的光线变化角度（景深）
+	FocusDist                  float64                // 相机观察点与完美焦点平面之间的距离
+	Aperture                   ApertureI              // 光圈形状，决定散景形状
+	OpticalVignetting          float64                // 光学渐晕（猫眼）强度，0为关闭，1约为图像角落处光圈被遮挡一半
+	ShutterOpen, ShutterClose  float64                // 快门打开与关闭的时刻
+	ShutterShape               ShutterShape           // 快门形状
+	MotionBlur                 bool                   // 运动模糊，关闭时射线时刻固定为ShutterOpen
+	CameraCenter               Point                  // 相机位置
+	LookAt                     Point                  // 光线出发点
+	LookFrom                   Point                  // 焦点
+	ViewportU                  Vec3                   // 视口水平长度向量
+	ViewportV                  Vec3                   // 视口垂直长度向量
+	PixelDeltaU                Vec3                   // 像素水平间隔
+	PixelDeltaV                Vec3                   // 像素垂直间隔
+	ViewportUpperLeft          Vec3                   // 视口左上向量
+	Pixel00Local               Vec3                   // 视口原点
+	world                      Scenes                 // 场景
+	IsAntialiased              bool                   // 抗锯齿
+	Filter                     FilterI                // 像素重建滤波器
+	Denoiser                   *Denoiser              // 降噪后处理，nil为关闭
+	BackgroundColor            *Color                 // 纯色背景，nil时为天空渐变
+	Progress                   utils.ProgressReporter // 渲染进度汇报，nil时不汇报
+	u, v, w, vup               Vec3                   // Camera frame basis vectors and Camera-relative "up" direction
 	defocusDistU, defocusDistV Vec3
+	aovEnabled                 bool          // 是否输出辅助缓冲
+	aov                        *AOVBuffer    // 最近一次渲染的辅助缓冲
+	aovIDs                     *aovIDs       // 物体/材质编号表
+	bvhBuildTime               time.Duration // 最近一次构建BVH的耗时
+	stats                      RenderStats   // 最近一次渲染的统计
 }
 
 func (c *Camera) Add(hittableItem ...HittableItemI) {
@@ -106,14 +122,26 @@ func NewCamera(lookAt, lookFrom Point, aspectRatio, vFov float64, imageWidth, sa
 		PixelDeltaV:       pixelDeltaV,
 		ViewportUpperLeft: viewportUpperLeft,
 		Pixel00Local:      pixel00Local,
-		LookAt:            Point{0, 0, -1},
 		// 抗锯齿开启
 		IsAntialiased:     isAntialiased,
 		PixelSamplesScale: 1.0 / float64(samplesPerPixel),
+		Filter:            NewBoxFilter(0.5),
+		Model:             ThinLensModel{},
 		DefocusAngle:      defocusAngle,
+		Aperture:          CircularAperture{},
+		ShutterOpen:       0,
+		ShutterClose:      1,
+		ShutterShape:      BoxShutter,
+		MotionBlur:        true,
+		VFov:              vFov,
+		LookAt:            lookAt,
 		FocusDist:         focusDist,
 		defocusDistU:      u.MultiplicationNum(defocusRadius),
 		defocusDistV:      v.MultiplicationNum(defocusRadius),
+		u:                 u,
+		v:                 v,
+		w:                 w,
+		vup:               vup,
 		world: Scenes{
 			HittableList: nil,
 			HittableAABB: &AABB{
@@ -125,88 +153,265 @@ func NewCamera(lookAt, lookFrom Point, aspectRatio, vFov float64, imageWidth, sa
 	}
 }
 
+// SetCameraModel 设置投影模型，透视与正交投影会同时更新视口
+func (c *Camera) SetCameraModel(model CameraModelI) {
+	c.Model = model
+	c.updateViewport()
+}
+
+// updateViewport 按当前投影模型重新计算视口，全景类模型不使用视口，按透视投影计算
+func (c *Camera) updateViewport() {
+	switch m := c.Model.(type) {
+	case OrthographicModel:
+		c.setViewport(m.ViewHeight)
+	case *OrthographicModel:
+		c.setViewport(m.ViewHeight)
+	default:
+		c.setViewport(2 * math.Tan(utils.Degrees2Radians(c.VFov)/2) * c.FocusDist)
+	}
+}
+
+// Orient 重新设置相机位置与观察点，并重新计算相机坐标系、散焦盘与视口
+func (c *Camera) Orient(lookFrom, lookAt Point) {
+	c.CameraCenter = lookFrom
+	c.LookFrom = lookFrom
+	c.LookAt = lookAt
+	c.vup = Vec3{0, 1, 0}
+	c.w = Vec3(lookFrom).Sub(Vec3(lookAt)).Normalize()
+	c.u = c.vup.Cross(c.w).Normalize()
+	c.v = c.w.Cross(c.u)
+	defocusRadius := c.FocusDist * math.Tan(utils.Degrees2Radians(c.DefocusAngle/2))
+	c.defocusDistU = c.u.MultiplicationNum(defocusRadius)
+	c.defocusDistV = c.v.MultiplicationNum(defocusRadius)
+	c.updateViewport()
+}
+
+// SetOrthographic 切换为正交投影，所有光线平行于观察方向，从视口平面上的对应位置发出
+func (c *Camera) SetOrthographic(viewHeight float64) {
+	c.SetCameraModel(OrthographicModel{ViewHeight: viewHeight})
+}
+
+// SetPerspective 切换回由VFov决定的透视投影
+func (c *Camera) SetPerspective() {
+	c.SetCameraModel(ThinLensModel{})
+}
+
+// setViewport 按视口高度重新计算视口与像素间隔，视口位于焦平面上
+func (c *Camera) setViewport(viewportHeight float64) {
+	c.ViewportHeight = viewportHeight
+	c.ViewportWidth = viewportHeight * c.AspectRatio
+	c.ViewportU = c.u.MultiplicationNum(c.ViewportWidth)
+	c.ViewportV = c.v.MultiplicationNum(-c.ViewportHeight)
+	c.PixelDeltaU = c.ViewportU.Div(float64(c.ImageWidth))
+	c.PixelDeltaV = c.ViewportV.Div(float64(c.ImageHeight))
+	center := Vec3(c.CameraCenter).Sub(c.w.MultiplicationNum(c.FocusDist))
+	c.ViewportUpperLeft = center.Sub(c.ViewportU.Div(2.0)).Sub(c.ViewportV.Div(2.0))
+	c.Pixel00Local = c.ViewportUpperLeft.Add(c.PixelDeltaV.MultiplicationNum(0.5).Add(c.PixelDeltaU.MultiplicationNum(0.5)))
+}
+
+// SetImageSize 修改输出分辨率，宽高比随之改变，视场角保持不变
+func (c *Camera) SetImageSize(width, height int) {
+	c.ImageWidth = width
+	c.ImageHeight = height
+	c.AspectRatio = float64(width) / float64(height)
+	c.updateViewport()
+}
+
+// SetSamplesPerPixel 修改每像素采样数
+func (c *Camera) SetSamplesPerPixel(samplesPerPixel int) {
+	c.SamplesPerPixel = samplesPerPixel
+	c.PixelSamplesScale = 1.0 / float64(samplesPerPixel)
+}
+
+// Objects 场景顶层物体数量
+func (c *Camera) Objects() int {
+	return len(c.world.HittableList)
+}
+
+// SetBackground 设置纯色背景，只靠光源照亮的场景使用黑色
+func (c *Camera) SetBackground(color Color) {
+	c.BackgroundColor = &color
+}
+
+// SetFilter 设置像素重建滤波器，滤波半径由滤波器自身决定
+func (c *Camera) SetFilter(filter FilterI) {
+	c.Filter = filter
+}
+
 func (c *Camera) EnabledBVH(enabled bool) {
 	if enabled {
 		c.world.EnabledBVH = true
-		c.world.BVH = NewBVHNode(c.world.HittableList)
+		start := time.Now()
+		c.world.BuildBVH()
+		c.bvhBuildTime = time.Since(start)
 	} else {
 		c.world.EnabledBVH = false
 	}
 }
 
-func (c *Camera) Render(name string) {
-	var ppm = utils.PPMImage{
-		Width:  c.ImageWidth,
-		Height: c.ImageHeight,
-		Max:    255,
+// BVHEnabled 是否使用BVH求交
+func (c *Camera) BVHEnabled() bool {
+	return c.world.EnabledBVH
+}
+
+// EnabledAOV 开启后渲染时在首次击中点记录反照率、法线、位置、深度和物体/材质编号，并与渲染结果一同保存
+func (c *Camera) EnabledAOV(enabled bool) {
+	c.aovEnabled = enabled
+}
+
+// AOV 返回最近一次渲染的辅助缓冲，未开启时为nil
+func (c *Camera) AOV() *AOVBuffer {
+	return c.aov
+}
+
+// SetDenoiser 设置渲染完成后的降噪处理，nil表示关闭；降噪需要的辅助缓冲会自动收集
+func (c *Camera) SetDenoiser(denoiser *Denoiser) {
+	c.Denoiser = denoiser
+}
+
+func (c *Camera) prepareAOV() {
+	if !c.aovEnabled && c.Denoiser == nil {
+		c.aov = nil
+		return
+	}
+	c.aov = NewAOVBuffer(c.ImageWidth, c.ImageHeight)
+	c.aovIDs = newAOVIDs(c.world.HittableList)
+}
+
+// addSample 累积采样到胶片，开启AOV时同时累积辅助缓冲
+func (c *Camera) addSample(film *Film, i, j int, sample FilmSample) {
+	film.AddSample(sample)
+	if c.aov != nil && sample.AOV != nil {
+		c.aov.Add(i, j, *sample.AOV)
+	}
+}
+
+// resolve 解析胶片并按需降噪
+func (c *Camera) resolve(film *Film) []Color {
+	start := time.Now()
+	colors := film.Resolve()
+	if c.aov != nil {
+		c.aov.Resolve()
+	}
+	c.stats.Resolve = time.Since(start)
+	if c.Denoiser != nil {
+		start = time.Now()
+		colors = c.Denoiser.Denoise(colors, film.Width, film.Height, c.aov)
+		c.stats.Denoise = time.Since(start)
+	}
+	return colors
+}
+
+// beginStats 开始新一次渲染的统计
+func (c *Camera) beginStats() {
+	c.stats = RenderStats{BVHBuild: c.bvhBuildTime}
+}
+
+// Stats 最近一次渲染的统计
+func (c *Camera) Stats() RenderStats {
+	return c.stats
+}
+
+// SaveImage 按扩展名选择格式保存渲染结果，开启AOV时辅助缓冲保存在同一目录，见 AOVBuffer.Save
+func (c *Camera) SaveImage(path string, colors []Color) error {
+	start := time.Now()
+	defer func() { c.stats.Save = time.Since(start) }()
+	if err := SaveColors(path, c.ImageWidth, c.ImageHeight, colors); err != nil {
+		return err
+	}
+	if c.aovEnabled {
+		return c.aov.Save(path)
 	}
-	var pixels = make([]utils.Pixel, 0, c.ImageHeight*c.ImageWidth)
-	bar := utils.NewProgressBar(int64(c.ImageWidth*c.ImageHeight), 50)
+	return nil
+}
+
+// Render 单线程渲染并按扩展名保存到path，返回渲染统计
+func (c *Camera) Render(path string) (RenderStats, error) {
+	err := c.SaveImage(path, c.RenderToColors())
+	return c.stats, err
+}
+
+// RenderToColors 单线程渲染，返回按行存储的线性颜色（已降噪），不写文件
+func (c *Camera) RenderToColors() []Color {
+	c.beginStats()
+	start := time.Now()
+	counters := &rayCounters{}
+	seed := utils.RenderSeed()
+	rng := utils.NewPixelRand()
+	film := NewFilm(c.ImageWidth, c.ImageHeight, c.Filter)
+	c.prepareAOV()
+	progress := c.newProgressTracker()
 	for j := 0; j < c.ImageHeight; j++ { // 行
 		for i := 0; i < c.ImageWidth; i++ { // 列
-			var color = Color{}
+			rng.Seed(utils.PixelSeed(seed, i+j*c.ImageWidth))
 			if c.IsAntialiased {
-				for _ = range c.SamplesPerPixel {
-					var r = c.GetRay(i, j)
-					color = Color(Vec3(color).Add(Vec3(c.RayColor(r, c.MaxDepth))))
+				for _, sample := range c.samplePixel(i, j, counters, rng) {
+					c.addSample(film, i, j, sample)
 				}
-				color = Color(Vec3(color).MultiplicationNum(c.PixelSamplesScale))
 			} else {
-				pixelCenter := c.Pixel00Local.Add(c.PixelDeltaU.MultiplicationNum(float64(i))).Add(c.PixelDeltaV.MultiplicationNum(float64(j)))
-				rayDirection := pixelCenter.Sub(Vec3(c.CameraCenter))
-				ray := NewRay(c.CameraCenter, rayDirection)
-				color = c.RayColor(&ray, c.MaxDepth) // 单线程
+				ray := c.centerRay(i, j)
+				c.addSample(film, i, j, c.traceSample(ray, float64(i), float64(j), counters, rng)) // 单线程
 			}
-			bar.Add(1)
-			pixels = append(pixels, color.Color2Pixel())
+			progress.Add(1)
 		}
 	}
-	ppm.Full(pixels)
-	ppm.FastWriteAndSave(name)
+	progress.Finish()
+	counters.flush(&c.stats)
+	c.stats.Trace = time.Since(start)
+	return c.resolve(film)
+}
 
+// newProgressTracker 按像素计数的进度，每个像素完成后调用一次Add
+func (c *Camera) newProgressTracker() *utils.ProgressTracker {
+	return utils.NewProgressTracker(int64(c.ImageWidth*c.ImageHeight), c.Progress, utils.DefaultProgressInterval)
 }
 
 // MultithreadedRender 多线程渲染，不一定速度会更快，开销全花在通信上面
-func (c *Camera) MultithreadedRender(name string, maxWorkers, buffer int) {
+func (c *Camera) MultithreadedRender(path string, maxWorkers, buffer int) (RenderStats, error) {
+	err := c.SaveImage(path, c.MultithreadedRenderToColors(maxWorkers, buffer))
+	return c.stats, err
+}
+
+// MultithreadedRenderToColors 多线程渲染，返回按行存储的线性颜色（已降噪），不写文件
+func (c *Camera) MultithreadedRenderToColors(maxWorkers, buffer int) []Color {
+	c.beginStats()
+	start := time.Now()
 	wgTask := sync.WaitGroup{}
 	wgWorker := sync.WaitGroup{}
-	var ppm = utils.PPMImage{
-		Width:  c.ImageWidth,
-		Height: c.ImageHeight,
-		Max:    255,
-	}
-	var pixels = make([]utils.Pixel, c.ImageHeight*c.ImageWidth) // 这里与上面的Render不同，不是进行Cap分配，是直接进行初始化赋值这么多个
+	film := NewFilm(c.ImageWidth, c.ImageHeight, c.Filter) // 只在结果协程中写入，无需加锁
+	c.prepareAOV()
 	colorTaskChan := make(chan ColorTask, c.ImageHeight*c.ImageWidth)
 	colorResChan := make(chan ColorRes, buffer)
 
-	pb := utils.NewProgressBar(int64(c.ImageWidth*c.ImageHeight), 50)
+	progress := c.newProgressTracker()
+	seed := utils.RenderSeed() // 像素按序号播种，结果与协程数和领取顺序无关
 	// worker
 	for w := 0; w < maxWorkers; w++ {
 		wgWorker.Add(1)
-		go func(resChan chan ColorRes, taskChan chan ColorTask, pb *utils.ProgressBar, w *sync.WaitGroup) {
+		go func(resChan chan ColorRes, taskChan chan ColorTask, progress *utils.ProgressTracker, w *sync.WaitGroup) {
 			defer wgWorker.Done()
+			counters := &rayCounters{} // 每个协程独立计数，结束时合并
+			defer counters.flush(&c.stats)
+			rng := utils.NewPixelRand() // 每个协程独立的随机数生成器
 			for task := range taskChan {
 				// 处理完直接发送，直到taskChan没东西或关闭
-				var color = Color{}
+				rng.Seed(utils.PixelSeed(seed, task.WidthIndex+task.HeightIndex*c.ImageWidth))
+				var samples []FilmSample
 				if c.IsAntialiased {
-					for _ = range c.SamplesPerPixel {
-						var r = c.GetRay(task.WidthIndex, task.HeightIndex)
-						color = Color(Vec3(color).Add(Vec3(c.RayColor(r, c.MaxDepth))))
-					}
-					color = Color(Vec3(color).MultiplicationNum(c.PixelSamplesScale))
+					samples = c.samplePixel(task.WidthIndex, task.HeightIndex, counters, rng)
 				} else {
-					color = c.RayColor(task.R, c.MaxDepth)
+					samples = []FilmSample{c.traceSample(task.R, float64(task.WidthIndex), float64(task.HeightIndex), counters, rng)}
 				}
-				pb.Add(1)
+				progress.Add(1)
 				//println(task.WidthIndex, " ", task.HeightIndex)
 				resChan <- ColorRes{
-					C:           color,
+					Samples:     samples,
 					WidthIndex:  task.WidthIndex,
 					HeightIndex: task.HeightIndex,
 					Index:       task.WidthIndex + task.HeightIndex*c.ImageWidth,
 				}
 			}
-		}(colorResChan, colorTaskChan, pb, &wgWorker)
+		}(colorResChan, colorTaskChan, progress, &wgWorker)
 	}
 
 	// 协程处理结果
@@ -215,7 +420,9 @@ func (c *Camera) MultithreadedRender(name string, maxWorkers, buffer int) {
 		defer wgTask.Done()
 		for colorRes := range colorResChan {
 			//println(colorRes.WidthIndex, " ", colorRes.HeightIndex, " ", colorRes.Index)
-			pixels[colorRes.HeightIndex*c.ImageWidth+colorRes.WidthIndex] = colorRes.C.Color2Pixel()
+			for _, sample := range colorRes.Samples {
+				c.addSample(film, colorRes.WidthIndex, colorRes.HeightIndex, sample)
+			}
 		}
 	}()
 
@@ -223,12 +430,10 @@ func (c *Camera) MultithreadedRender(name string, maxWorkers, buffer int) {
 	for j := 0; j < c.ImageHeight; j++ { // 行
 		for i := 0; i < c.ImageWidth; i++ { // 列
 			//wgTask.Add(1)
-			pixelCenter := c.Pixel00Local.Add(c.PixelDeltaU.MultiplicationNum(float64(i))).Add(c.PixelDeltaV.MultiplicationNum(float64(j)))
-			rayDirection := pixelCenter.Sub(Vec3(c.CameraCenter))
-			ray := NewRay(c.CameraCenter, rayDirection)
+			ray := c.centerRay(i, j)
 			//println(i, " ", j,)
 			colorTaskChan <- ColorTask{
-				R:           &ray,
+				R:           ray,
 				WidthIndex:  i,
 				HeightIndex: j,
 			}
@@ -245,64 +450,141 @@ func (c *Camera) MultithreadedRender(name string, maxWorkers, buffer int) {
 
 	// 等待结果处理完
 	wgTask.Wait()
-	fmt.Println("All Pixels have been rendered")
-	ppm.Full(pixels)
-	ppm.FastWriteAndSave(name)
+	progress.Finish()
+	c.stats.Trace = time.Since(start)
+	return c.resolve(film)
 }
 
 func (c *Camera) RayColor(r *Ray, maxDepth int) Color {
 	if maxDepth <= 0 {
 		return Color{0, 0, 0}
 	}
-	if c.world.EnabledBVH {
-		hit, hitRecord := c.world.BVH.Hittable(*r, NewInterval(1e-5, utils.Infinity))
-		if hit {
-			hit, attenuation, scattered := hitRecord.Material.Scatter(r, hitRecord)
-			if hit {
-				return Color(Vec3(attenuation).MultiplicationVec3(Vec3(c.RayColor(scattered, maxDepth-1))))
-			}
-			return Color{}
-		}
-	} else {
-		hit, hitRecord := c.world.HitAnything(*r, NewInterval(1e-5, utils.Infinity))
-		if hit {
-			hit, attenuation, scattered := hitRecord.Material.Scatter(r, hitRecord)
-			if hit {
-				return Color(Vec3(attenuation).MultiplicationVec3(Vec3(c.RayColor(scattered, maxDepth-1))))
-			}
-			return Color{}
-		}
+	r.counters.ray()
+	if hit, hitRecord := c.world.Hit(*r, NewInterval(1e-5, utils.Infinity)); hit {
+		return c.shade(r, hitRecord, maxDepth)
+	}
+	return c.Background(r)
+}
+
+// rayColorWithAOV 与RayColor相同，额外返回首次击中点的辅助信息
+func (c *Camera) rayColorWithAOV(r *Ray, maxDepth int) (Color, *AOVSample) {
+	if maxDepth <= 0 {
+		return Color{}, &AOVSample{}
+	}
+	r.counters.ray()
+	hit, hitRecord := c.world.Hit(*r, NewInterval(1e-5, utils.Infinity))
+	if !hit {
+		background := c.Background(r)
+		return background, &AOVSample{Albedo: background}
+	}
+	return c.shade(r, hitRecord, maxDepth), c.aovIDs.sample(hitRecord)
+}
+
+// shade 在击中点散射并继续追踪
+func (c *Camera) shade(r *Ray, hitRecord HitRecord, maxDepth int) Color {
+	var emitted Color
+	if emitter, ok := hitRecord.Material.(EmitterI); ok {
+		emitted = emitter.Emitted(hitRecord.U, hitRecord.V, hitRecord.HitPoint)
+	}
+	hit, attenuation, scattered := hitRecord.Material.Scatter(r, hitRecord)
+	if hit {
+		scattered.counters = r.counters
+		scattered.rng = r.rng
+		return Color(Vec3(emitted).Add(Vec3(attenuation).MultiplicationVec3(Vec3(c.RayColor(scattered, maxDepth-1)))))
+	}
+	return emitted
+}
+
+// Background 未击中任何物体时的背景颜色，默认为天空渐变
+func (c *Camera) Background(r *Ray) Color {
+	if c.BackgroundColor != nil {
+		return *c.BackgroundColor
 	}
 	var directionNormalized = r.Direction.Normalize()
 	a := 0.5 * (directionNormalized.Y + 1.0)
 	return Color(Vec3(Color{1.0, 1.0, 1.0}).MultiplicationNum(1 - a).Add(Vec3{0.5, 0.7, 1.0}.MultiplicationNum(a)))
+}
 
+// samplePixel 对像素(i,j)进行SamplesPerPixel次随机采样，返回带图像坐标的采样结果
+func (c *Camera) samplePixel(i, j int, counters *rayCounters, rng *rand.Rand) []FilmSample {
+	samples := make([]FilmSample, 0, c.SamplesPerPixel)
+	for _ = range c.SamplesPerPixel {
+		offset := SampleSquare(rng)
+		r := c.sampleRay(i, j, offset, rng)
+		samples = append(samples, c.traceSample(r, float64(i)+offset.X, float64(j)+offset.Y, counters, rng))
+	}
+	return samples
 }
 
 func (c *Camera) GetRay(i, j int) *Ray {
 	// 从散焦盘构造一条指向像素位置i, j周围随机采样点的相机射线。
-	var offset = SampleSquare()
+	return c.GetRayWithOffset(i, j, SampleSquare(nil))
+}
+
+// traceSample 追踪一条相机射线，开启AOV时同时记录首次击中信息；r为nil时为黑色
+func (c *Camera) traceSample(r *Ray, x, y float64, counters *rayCounters, rng *rand.Rand) FilmSample {
+	if r == nil {
+		sample := FilmSample{X: x, Y: y}
+		if c.aov != nil {
+			sample.AOV = &AOVSample{}
+		}
+		return sample
+	}
+	r.counters = counters
+	r.rng = rng
+	counters.primaryRay()
+	if c.aov == nil {
+		return FilmSample{X: x, Y: y, C: c.RayColor(r, c.MaxDepth)}
+	}
+	color, aov := c.rayColorWithAOV(r, c.MaxDepth)
+	return FilmSample{X: x, Y: y, C: color, AOV: aov}
+}
+
+// centerRay 不抗锯齿时穿过像素中心的射线，不考虑景深和运动模糊
+func (c *Camera) centerRay(i, j int) *Ray {
+	ray, ok := c.Model.GenerateRay(c, float64(i), float64(j), false, nil)
+	if !ok {
+		return nil
+	}
+	ray.TM = c.ShutterOpen
+	return &ray
+}
+
+// GetRayWithOffset 构造一条指向像素(i,j)中心偏移offset处的相机射线，投影模型无对应射线时返回nil
+func (c *Camera) GetRayWithOffset(i, j int, offset Vec3) *Ray {
+	return c.sampleRay(i, j, offset, nil)
+}
+
+// sampleRay 同GetRayWithOffset，镜头和快门采样使用rng
+func (c *Camera) sampleRay(i, j int, offset Vec3, rng *rand.Rand) *Ray {
 	// 在每个像素邻域内进行随机采样
-	pixelSample := c.Pixel00Local.Add(c.PixelDeltaU.MultiplicationNum(float64(i) + offset.X)).Add(c.PixelDeltaV.MultiplicationNum(float64(j) + offset.Y))
-	rayOrigin := c.CameraCenter
-	if c.DefocusAngle > 0 {
-		rayOrigin = c.DefocusDiskSample()
+	ray, ok := c.Model.GenerateRay(c, float64(i)+offset.X, float64(j)+offset.Y, true, rng)
+	if !ok {
+		return nil
 	}
-	rayDirection := pixelSample.Sub(Vec3(rayOrigin))
-	rayTime := utils.Random()
-	ray := NewRayWithTime(rayOrigin, rayDirection, rayTime)
+	ray.TM = c.sampleTime(rng)
 	return &ray
 }
 
-// SampleSquare x,y in [-0.5,0.5]
-func SampleSquare() Vec3 {
+// SampleSquare x,y in [-0.5,0.5]，rng为nil时使用全局随机源
+func SampleSquare(rng *rand.Rand) Vec3 {
 	return Vec3{
-		X: utils.Random() - 0.5,
-		Y: utils.Random() - 0.5,
+		X: utils.RandomFrom(rng) - 0.5,
+		Y: utils.RandomFrom(rng) - 0.5,
 	}
 }
 
 func (c *Camera) DefocusDiskSample() Point {
-	p := RandomInUnitDisk()
+	return c.lensPoint(c.Aperture.Sample(nil))
+}
+
+// lensPoint 将光圈上的采样点p转换为世界坐标下的光线起点
+func (c *Camera) lensPoint(p Vec3) Point {
 	return Point(Vec3(c.CameraCenter).Add(c.defocusDistU.MultiplicationNum(p.X)).Add(c.defocusDistV.MultiplicationNum(p.Y)))
 }
+
+// SetAperture 设置光圈形状与光学渐晕强度
+func (c *Camera) SetAperture(aperture ApertureI, opticalVignetting float64) {
+	c.Aperture = aperture
+	c.OpticalVignetting = opticalVignetting
+}
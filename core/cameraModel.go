@@ -0,0 +1,107 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+/*
+相机投影模型：
+Camera 负责图像尺寸、相机坐标系(u,v,w)、采样和渲染流程，具体“像素 -> 射线”的映射交给投影模型。
+x,y 为连续像素坐标，像素(i,j)的中心为(i,j)，图像左上角为(-0.5,-0.5)。
+lens 为 false 时不做镜头采样（用于不抗锯齿时穿过像素中心的射线），rng 为镜头采样使用的随机数生成器。
+返回 false 表示该像素位置没有对应的射线（如鱼眼成像圆之外），渲染为黑色。
+*/
+
+// CameraModelI 相机投影模型接口
+type CameraModelI interface {
+	GenerateRay(c *Camera, x, y float64, lens bool, rng *rand.Rand) (Ray, bool)
+}
+
+// ThinLensModel 透视投影（针孔），DefocusAngle>0时为薄透镜景深
+type ThinLensModel struct{}
+
+func (ThinLensModel) GenerateRay(c *Camera, x, y float64, lens bool, rng *rand.Rand) (Ray, bool) {
+	pixelSample := c.Pixel00Local.Add(c.PixelDeltaU.MultiplicationNum(x)).Add(c.PixelDeltaV.MultiplicationNum(y))
+	rayOrigin := c.CameraCenter
+	if lens && c.DefocusAngle > 0 {
+		p := c.Aperture.Sample(rng)
+		if c.OpticalVignetting > 0 && !c.insideVignette(p, x, y) {
+			return Ray{}, false // 被镜筒遮挡
+		}
+		rayOrigin = c.lensPoint(p)
+	}
+	return NewRay(rayOrigin, pixelSample.Sub(Vec3(rayOrigin))), true
+}
+
+// OrthographicModel 正交投影，光线从相机平面上与采样点对应的位置平行射出
+type OrthographicModel struct {
+	ViewHeight float64 // 视口高度（世界单位）
+}
+
+func (OrthographicModel) GenerateRay(c *Camera, x, y float64, lens bool, rng *rand.Rand) (Ray, bool) {
+	pixelSample := c.Pixel00Local.Add(c.PixelDeltaU.MultiplicationNum(x)).Add(c.PixelDeltaV.MultiplicationNum(y))
+	return NewRay(Point(pixelSample.Add(c.w.MultiplicationNum(c.FocusDist))), c.w.MultiplicationNum(-1)), true
+}
+
+// EquirectangularModel 等距柱状投影的360°全景，水平覆盖360°经度，垂直覆盖180°纬度，图像中心为观察方向
+type EquirectangularModel struct{}
+
+func (EquirectangularModel) GenerateRay(c *Camera, x, y float64, lens bool, rng *rand.Rand) (Ray, bool) {
+	phi := 2*math.Pi*(x+0.5)/float64(c.ImageWidth) - math.Pi // 经度 [-π,π]
+	theta := math.Pi * (y + 0.5) / float64(c.ImageHeight)    // 与正上方的夹角 [0,π]
+	direction := c.u.MultiplicationNum(math.Sin(theta) * math.Sin(phi)).
+		Add(c.v.MultiplicationNum(math.Cos(theta))).
+		Sub(c.w.MultiplicationNum(math.Sin(theta) * math.Cos(phi)))
+	return NewRay(c.CameraCenter, direction), true
+}
+
+type FisheyeMapping string
+
+const (
+	EquidistantMapping FisheyeMapping = "equidistant" // r ∝ θ
+	EquisolidMapping   FisheyeMapping = "equisolid"   // r ∝ sin(θ/2)，等立体角
+)
+
+// FisheyeModel 鱼眼投影，成像圆内切于图像短边
+type FisheyeModel struct {
+	FOV     float64 // 视场角（度），可以超过180
+	Mapping FisheyeMapping
+}
+
+func NewFisheyeModel(fov float64, mapping FisheyeMapping) (*FisheyeModel, error) {
+	if fov <= 0 || fov > 360 {
+		return nil, fmt.Errorf("fisheye fov %v out of range (0, 360]", fov)
+	}
+	switch mapping {
+	case EquidistantMapping, EquisolidMapping:
+	default:
+		return nil, fmt.Errorf("unknown fisheye mapping %q", mapping)
+	}
+	return &FisheyeModel{FOV: fov, Mapping: mapping}, nil
+}
+
+func (f FisheyeModel) GenerateRay(c *Camera, x, y float64, lens bool, rng *rand.Rand) (Ray, bool) {
+	radius := float64(min(c.ImageWidth, c.ImageHeight)) / 2
+	nx := (x + 0.5 - float64(c.ImageWidth)/2) / radius
+	ny := -(y + 0.5 - float64(c.ImageHeight)/2) / radius
+	r := math.Sqrt(nx*nx + ny*ny)
+	if r > 1 {
+		return Ray{}, false
+	}
+	halfFov := utils.Degrees2Radians(f.FOV) / 2
+	var theta float64 // 与观察方向的夹角
+	switch f.Mapping {
+	case EquisolidMapping:
+		theta = 2 * math.Asin(r*math.Sin(halfFov/2))
+	default:
+		theta = r * halfFov
+	}
+	phi := math.Atan2(ny, nx)
+	direction := c.u.MultiplicationNum(math.Sin(theta) * math.Cos(phi)).
+		Add(c.v.MultiplicationNum(math.Sin(theta) * math.Sin(phi))).
+		Sub(c.w.MultiplicationNum(math.Cos(theta)))
+	return NewRay(c.CameraCenter, direction), true
+}
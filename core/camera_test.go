@@ -1,24 +1,69 @@
 package core
 
 import (
+	"RayTracingInOneWeekend/utils"
 	"math"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
+// 书中检验视野的场景：左红右蓝两个相切的球，90度视野下刚好占满画面中部
 func TestCamera(t *testing.T) {
-	camera := NewCamera(Point{0, 0, 0}, Point{0, 0, -1}, 16.0/9.0, 90, 160, 100, 20, true, 0.4, 10)
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 16.0/9.0, 90, 32, 16, 20, true, 0, 1)
 	R := math.Cos(math.Pi / 4)
-	s1 := Sphere{
-		Center:   Point{X: -R, Z: -1.0},
-		Radius:   R,
-		Material: LambertianReflectionMaterial{Albedo: Color{X: 1.0}},
+	s1 := NewSphere(Point{X: -R, Z: -1.0}, R).WithMaterial(LambertianReflectionMaterial{Albedo: Color{X: 1.0}})
+	s2 := NewSphere(Point{X: R, Z: -1.0}, R).WithMaterial(LambertianReflectionMaterial{Albedo: Color{Z: 1.0}})
+	camera.Add(s1, s2)
+	colors := camera.RenderToColors()
+
+	// 左半边偏红，右半边偏蓝
+	var left, right Color
+	for j := 0; j < camera.ImageHeight; j++ {
+		for i := 0; i < camera.ImageWidth; i++ {
+			c := colors[j*camera.ImageWidth+i]
+			if i < camera.ImageWidth/2 {
+				left = Color(Vec3(left).Add(Vec3(c)))
+			} else {
+				right = Color(Vec3(right).Add(Vec3(c)))
+			}
+		}
+	}
+	if left.X <= left.Z || right.Z <= right.X {
+		t.Errorf("left sum %v should be red and right sum %v blue", left, right)
+	}
+
+	path := filepath.Join(t.TempDir(), "camera.png")
+	stats, err := camera.Render(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.PrimaryRays != int64(camera.ImageWidth*camera.ImageHeight*camera.SamplesPerPixel) {
+		t.Errorf("primary rays = %d", stats.PrimaryRays)
 	}
-	s2 := Sphere{
-		Center:   Point{X: R, Z: -1.0},
-		Radius:   R,
-		Material: LambertianReflectionMaterial{Albedo: Color{Z: 1.0}},
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+	if _, err := camera.Render(filepath.Join(t.TempDir(), "camera.tiff")); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+	//camera.MultithreadedRender(path, 12, 10000000)
+}
+
+// 每个像素的随机序列由全局种子和像素序号决定，相同种子下单线程和多线程渲染结果完全一致
+func TestRenderIsReproducible(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 2, 90, 16, 4, 10, true, 0, 1)
+	camera.Add(
+		NewSphere(Point{X: -0.5, Z: -1}, 0.5).WithMaterial(DielectricMaterial{RefractionIndex: 1.5}),
+		NewSphere(Point{X: 0.5, Z: -1}, 0.5).WithMaterial(MetalMaterial{Albedo: Color{X: 0.8, Y: 0.8, Z: 0.8}, Fuzz: 0.3}),
+	)
+	utils.Seed(7)
+	first := camera.RenderToColors()
+	utils.Seed(7)
+	second := camera.MultithreadedRenderToColors(4, 16)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("pixel %d differs: %v vs %v", i, first[i], second[i])
+		}
 	}
-	camera.Add(&s1, &s2)
-	camera.Render()
-	//camera.MultithreadedRender(12, 10000000)
 }
@@ -16,7 +16,7 @@ type ColorTask struct {
 }
 
 type ColorRes struct {
-	C           Color
+	Samples     []FilmSample
 	WidthIndex  int
 	HeightIndex int
 	Index       int
@@ -43,3 +43,18 @@ func (c *Color) Linear2Gamma(gamma float64) {
 	c.Y = math.Pow(c.Y, 1.0/gamma)
 	c.Z = math.Pow(c.Z, 1.0/gamma)
 }
+
+// Colors2Pixels 批量将线性颜色转换为伽马校正后的像素
+func Colors2Pixels(colors []Color) []utils.Pixel {
+	pixels := make([]utils.Pixel, len(colors))
+	for i := range colors {
+		c := colors[i]
+		pixels[i] = c.Color2Pixel()
+	}
+	return pixels
+}
+
+// SaveColors 将线性颜色伽马校正后按path的扩展名保存
+func SaveColors(path string, width, height int, colors []Color) error {
+	return utils.SaveImage(path, width, height, Colors2Pixels(colors))
+}
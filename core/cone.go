@@ -0,0 +1,107 @@
+package core
+
+import "math"
+
+/*
+圆锥：底面圆心Center，半径Radius，顶点在Center上方Height处，Capped为true时带底面。
+记k=R/H，侧面方程 x²+z² = k²(H-y)²（相对底面圆心），代入射线得到关于t的二次方程，交点高度需在[0,Height]内。
+外法线为隐式方程的梯度 (x, k²(H-y), z)，在顶点处退化，取+Y。其他朝向通过 Instance 旋转得到。
+侧面UV：U为绕Y轴的角度，V为高度比例；底面UV按x、z坐标平铺到[0,1]。
+*/
+type Cone struct {
+	Center   Point
+	Radius   float64
+	Height   float64
+	Capped   bool
+	Material MaterialI
+	AABB     *AABB
+}
+
+func NewCone(center Point, radius, height float64) *Cone {
+	cone := &Cone{
+		Center: center,
+		Radius: radius,
+		Height: height,
+		Capped: true,
+	}
+	cone.SetBoundingBox(nil)
+	return cone
+}
+
+func (cone *Cone) WithMaterial(mat MaterialI) *Cone {
+	cone.Material = mat
+	return cone
+}
+
+func (cone *Cone) GetMaterial() MaterialI {
+	return cone.Material
+}
+
+func (cone *Cone) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		cone.AABB = aabb
+		return
+	}
+	cone.AABB = axialAABB(cone.Center, cone.Radius, cone.Height)
+}
+
+func (cone *Cone) GetBoundingBox() *AABB {
+	return cone.AABB
+}
+
+func (cone *Cone) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	o := Vec3(ray.Origin).Sub(Vec3(cone.Center))
+	d := ray.Direction
+	k := cone.Radius / cone.Height
+	k2 := k * k
+	h0 := cone.Height - o.Y // 射线起点到顶点的高度差
+
+	// 侧面：a t² + 2h t + c = 0
+	a := d.X*d.X + d.Z*d.Z - k2*d.Y*d.Y
+	h := o.X*d.X + o.Z*d.Z + k2*h0*d.Y
+	c := o.X*o.X + o.Z*o.Z - k2*h0*h0
+	var roots []float64
+	if math.Abs(a) > 1e-12 {
+		if r, ok := quadraticRoots(a, h, c); ok {
+			roots = r[:]
+		}
+	} else if math.Abs(h) > 1e-12 {
+		// 射线与母线平行，只有一个交点
+		roots = []float64{-c / (2 * h)}
+	}
+	for _, t := range roots {
+		y := o.Y + t*d.Y
+		if !rayT.Surrounds(t) || y < 0 || y > cone.Height {
+			continue
+		}
+		p := o.Add(d.MultiplicationNum(t))
+		normal := Vec3{X: p.X, Y: k2 * (cone.Height - y), Z: p.Z}
+		if normal.LengthSquared() < 1e-24 {
+			normal = Vec3{Y: 1}
+		}
+		hit = true
+		rayT.Max = t
+		hitRecord.Time = t
+		hitRecord.setFaceNormal(ray, normal.Normalize())
+		hitRecord.U = angleUV(-p.Z, p.X)
+		hitRecord.V = y / cone.Height
+		break
+	}
+
+	// 底面
+	if cone.Capped {
+		if onCap, t, u, v := capHit(o, d, 0, cone.Radius, rayT); onCap {
+			hit = true
+			hitRecord.Time = t
+			hitRecord.setFaceNormal(ray, Vec3{Y: -1})
+			hitRecord.U, hitRecord.V = u, v
+		}
+	}
+	if !hit {
+		return false, hitRecord
+	}
+	hitRecord.HitPoint = ray.At(hitRecord.Time)
+	hitRecord.Material = cone.Material
+	return true, hitRecord
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+)
+
+// CSGOperation 构造实体几何的布尔运算
+type CSGOperation string
+
+const (
+	CSGUnion        CSGOperation = "union"        // A ∪ B
+	CSGIntersection CSGOperation = "intersection" // A ∩ B
+	CSGDifference   CSGOperation = "difference"   // A - B
+)
+
+// contains 点在A、B内外的状态组合后是否在结果实体内
+func (op CSGOperation) contains(inA, inB bool) bool {
+	switch op {
+	case CSGUnion:
+		return inA || inB
+	case CSGIntersection:
+		return inA && inB
+	default:
+		return inA && !inB
+	}
+}
+
+/*
+构造实体几何：对两个封闭物体A、B做并、交、差运算，A、B可以是另一个CSG节点。
+沿射线逐个取出A、B的全部交点，正面交点表示进入物体、背面交点表示离开物体，
+第一个交点是背面时说明射线起点在物体内。按t从小到大合并两个物体的进出事件，
+运算结果的内外状态第一次发生变化的位置就是CSG的交点。
+击中记录中的法线总是与射线方向相对，FrontFace按结果实体重新设置，
+因此差运算中被减去的B的表面（进入B即离开结果）外法线自动翻转。
+Material不为nil时覆盖A、B各自的材质。
+*/
+type CSG struct {
+	Operation CSGOperation
+	A, B      HittableItemI
+	Material  MaterialI
+	AABB      *AABB
+}
+
+// csgMaxEvents 单次求交最多处理的进出事件数，防止退化几何导致死循环
+const csgMaxEvents = 64
+
+// csgEpsilon 取下一个交点时跳过当前交点的距离
+const csgEpsilon = 1e-7
+
+func NewCSG(operation CSGOperation, a, b HittableItemI) *CSG {
+	csg := &CSG{Operation: operation, A: a, B: b}
+	csg.SetBoundingBox(nil)
+	return csg
+}
+
+func NewUnion(a, b HittableItemI) *CSG {
+	return NewCSG(CSGUnion, a, b)
+}
+
+func NewIntersection(a, b HittableItemI) *CSG {
+	return NewCSG(CSGIntersection, a, b)
+}
+
+func NewDifference(a, b HittableItemI) *CSG {
+	return NewCSG(CSGDifference, a, b)
+}
+
+func (csg *CSG) WithMaterial(mat MaterialI) *CSG {
+	csg.Material = mat
+	return csg
+}
+
+func (csg *CSG) GetMaterial() MaterialI {
+	if csg.Material != nil {
+		return csg.Material
+	}
+	if holder, ok := csg.A.(MaterialHolderI); ok {
+		return holder.GetMaterial()
+	}
+	return nil
+}
+
+func (csg *CSG) Children() []HittableItemI {
+	return []HittableItemI{csg.A, csg.B}
+}
+
+// SetBoundingBox 传入nil时按运算计算：并为两者的并集，交为两者的交集，差为A的包围盒
+func (csg *CSG) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		csg.AABB = aabb
+		return
+	}
+	a, b := csg.A.GetBoundingBox(), csg.B.GetBoundingBox()
+	switch csg.Operation {
+	case CSGUnion:
+		csg.AABB = NewAABBFromAABB(a, b)
+	case CSGIntersection:
+		overlap := func(i, j Interval) Interval {
+			result := Interval{Min: max(i.Min, j.Min), Max: min(i.Max, j.Max)}
+			if result.Max < result.Min {
+				return Interval{Min: result.Min, Max: result.Min}
+			}
+			return result
+		}
+		csg.AABB = NewAABB(overlap(a.X, b.X), overlap(a.Y, b.Y), overlap(a.Z, b.Z))
+	default:
+		csg.AABB = a
+	}
+}
+
+func (csg *CSG) GetBoundingBox() *AABB {
+	return csg.AABB
+}
+
+func (csg *CSG) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	if !csg.AABB.HitInterval(&ray, rayT) {
+		return false, hitRecord
+	}
+	a := newCSGCursor(csg.A, ray, rayT.Min)
+	b := newCSGCursor(csg.B, ray, rayT.Min)
+	inside := csg.Operation.contains(a.inside, b.inside)
+	for range csgMaxEvents {
+		// 取A、B中较近的下一个交点
+		cursor := a
+		if !a.ok || (b.ok && b.record.Time < a.record.Time) {
+			cursor = b
+		}
+		if !cursor.ok || cursor.record.Time >= rayT.Max {
+			return false, hitRecord
+		}
+		hitRecord = cursor.record
+		cursor.inside = hitRecord.FrontFace
+		cursor.advance(ray)
+		if now := csg.Operation.contains(a.inside, b.inside); now != inside {
+			hitRecord.FrontFace = now
+			if csg.Material != nil {
+				hitRecord.Material = csg.Material
+			}
+			return true, hitRecord
+		}
+	}
+	return false, HitRecord{}
+}
+
+// csgCursor 沿射线依次取出一个物体的交点，并记录当前位置在物体内外
+type csgCursor struct {
+	item   HittableItemI
+	inside bool      // 当前位置（上一个交点之后）是否在物体内
+	ok     bool      // 是否还有下一个交点
+	record HitRecord // 下一个交点
+}
+
+func newCSGCursor(item HittableItemI, ray Ray, tMin float64) *csgCursor {
+	cursor := &csgCursor{item: item}
+	cursor.ok, cursor.record = item.Hittable(ray, NewInterval(tMin, utils.Infinity))
+	// 第一个交点是离开物体，说明起点在物体内
+	cursor.inside = cursor.ok && !cursor.record.FrontFace
+	return cursor
+}
+
+func (cursor *csgCursor) advance(ray Ray) {
+	t := cursor.record.Time
+	cursor.ok, cursor.record = cursor.item.Hittable(ray, NewInterval(t+csgEpsilon*math.Max(1, math.Abs(t)), utils.Infinity))
+}
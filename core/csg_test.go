@@ -0,0 +1,49 @@
+package core
+
+import "testing"
+
+func TestCSG(t *testing.T) {
+	sphere := func() HittableItemI { return NewSphere(Point{}, 1) }
+	drill := func() HittableItemI { return NewCylinder(Point{Y: -2}, 0.5, 4) }
+
+	difference := NewDifference(sphere(), drill())
+	if box := difference.GetBoundingBox(); box.Y != (Interval{-1, 1}) {
+		t.Errorf("difference bounding box %+v", box)
+	}
+	checkPrimitiveHits(t, difference, []primitiveHitTest{
+		{"sphere surface", NewRay(Point{Z: 5}, Vec3{Z: -1}), true, 4, Vec3{Z: 1}, true},
+		{"down the hole", NewRay(Point{Y: 5}, Vec3{Y: -1}), false, 0, Vec3{}, false},
+		{"beside the hole", NewRay(Point{X: 0.6, Y: 5}, Vec3{Y: -1}), true, 4.2, Vec3{X: 0.6, Y: 0.8}, true},
+		// 从孔中射出，击中被减去的圆柱面，外法线指向孔内
+		{"from the hole", NewRay(Point{}, Vec3{Z: 1}), true, 0.5, Vec3{Z: -1}, true},
+		{"inside the shell", NewRay(Point{Z: 0.75}, Vec3{Z: -1}), true, 0.25, Vec3{Z: 1}, false},
+	})
+
+	intersection := NewIntersection(sphere(), drill())
+	if box := intersection.GetBoundingBox(); box.X != (Interval{-0.5, 0.5}) || box.Y != (Interval{-1, 1}) {
+		t.Errorf("intersection bounding box %+v", box)
+	}
+	checkPrimitiveHits(t, intersection, []primitiveHitTest{
+		{"sphere cap", NewRay(Point{Y: 5}, Vec3{Y: -1}), true, 4, Vec3{Y: 1}, true},
+		{"cylinder wall", NewRay(Point{Z: 5}, Vec3{Z: -1}), true, 4.5, Vec3{Z: 1}, true},
+		{"outside the cylinder", NewRay(Point{X: 0.6, Y: 5}, Vec3{Y: -1}), false, 0, Vec3{}, false},
+	})
+
+	union := NewUnion(sphere(), drill())
+	if box := union.GetBoundingBox(); box.Y != (Interval{-2, 2}) || box.X != (Interval{-1, 1}) {
+		t.Errorf("union bounding box %+v", box)
+	}
+	checkPrimitiveHits(t, union, []primitiveHitTest{
+		{"cylinder cap", NewRay(Point{Y: 5}, Vec3{Y: -1}), true, 3, Vec3{Y: 1}, true},
+		{"sphere", NewRay(Point{Z: 5}, Vec3{Z: -1}), true, 4, Vec3{Z: 1}, true},
+		// 内部的圆柱面不属于并集表面
+		{"from inside", NewRay(Point{}, Vec3{X: 1}), true, 1, Vec3{X: -1}, false},
+	})
+
+	// 嵌套：在带孔的球上再减去一个沿X轴的孔
+	nested := NewDifference(difference, NewInstance(drill(), NewTransform(Vec3{}, Vec3{Z: 90}, Vec3{X: 1, Y: 1, Z: 1})))
+	checkPrimitiveHits(t, nested, []primitiveHitTest{
+		{"down the second hole", NewRay(Point{X: 5}, Vec3{X: -1}), false, 0, Vec3{}, false},
+		{"sphere surface", NewRay(Point{Z: 5}, Vec3{Z: -1}), true, 4, Vec3{Z: 1}, true},
+	})
+}
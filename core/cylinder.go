@@ -0,0 +1,139 @@
+package core
+
+import "math"
+
+/*
+圆柱：底面圆心Center，沿+Y轴高Height，半径Radius，Capped为true时带上下两个底面。
+侧面方程 x²+z²=R²（相对底面圆心），代入射线得到关于t的二次方程，交点高度需在[0,Height]内；
+底面是 y=0 与 y=Height 两个平面上半径为R的圆盘。其他朝向通过 Instance 旋转得到。
+侧面UV：U为绕Y轴的角度，V为高度比例；底面UV按x、z坐标平铺到[0,1]。
+*/
+type Cylinder struct {
+	Center   Point
+	Radius   float64
+	Height   float64
+	Capped   bool
+	Material MaterialI
+	AABB     *AABB
+}
+
+func NewCylinder(center Point, radius, height float64) *Cylinder {
+	cylinder := &Cylinder{
+		Center: center,
+		Radius: radius,
+		Height: height,
+		Capped: true,
+	}
+	cylinder.SetBoundingBox(nil)
+	return cylinder
+}
+
+func (cylinder *Cylinder) WithMaterial(mat MaterialI) *Cylinder {
+	cylinder.Material = mat
+	return cylinder
+}
+
+func (cylinder *Cylinder) GetMaterial() MaterialI {
+	return cylinder.Material
+}
+
+func (cylinder *Cylinder) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		cylinder.AABB = aabb
+		return
+	}
+	cylinder.AABB = axialAABB(cylinder.Center, cylinder.Radius, cylinder.Height)
+}
+
+func (cylinder *Cylinder) GetBoundingBox() *AABB {
+	return cylinder.AABB
+}
+
+func (cylinder *Cylinder) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	o := Vec3(ray.Origin).Sub(Vec3(cylinder.Center))
+	d := ray.Direction
+	r := cylinder.Radius
+
+	// 侧面
+	a := d.X*d.X + d.Z*d.Z
+	h := o.X*d.X + o.Z*d.Z
+	c := o.X*o.X + o.Z*o.Z - r*r
+	if a > 1e-12 {
+		if roots, ok := quadraticRoots(a, h, c); ok {
+			for _, t := range roots {
+				if y := o.Y + t*d.Y; rayT.Surrounds(t) && y >= 0 && y <= cylinder.Height {
+					p := o.Add(d.MultiplicationNum(t))
+					hit = true
+					rayT.Max = t
+					hitRecord.Time = t
+					hitRecord.setFaceNormal(ray, Vec3{X: p.X / r, Z: p.Z / r})
+					hitRecord.U = angleUV(-p.Z, p.X)
+					hitRecord.V = y / cylinder.Height
+					break
+				}
+			}
+		}
+	}
+
+	// 底面与顶面
+	if cylinder.Capped {
+		for _, capY := range [2]float64{0, cylinder.Height} {
+			if onCap, t, u, v := capHit(o, d, capY, r, rayT); onCap {
+				hit = true
+				rayT.Max = t
+				hitRecord.Time = t
+				normal := Vec3{Y: 1}
+				if capY == 0 {
+					normal = Vec3{Y: -1}
+				}
+				hitRecord.setFaceNormal(ray, normal)
+				hitRecord.U, hitRecord.V = u, v
+			}
+		}
+	}
+	if !hit {
+		return false, hitRecord
+	}
+	hitRecord.HitPoint = ray.At(hitRecord.Time)
+	hitRecord.Material = cylinder.Material
+	return true, hitRecord
+}
+
+// quadraticRoots 求 a t² + 2h t + c = 0 的两个实根（从小到大），a不能为0
+func quadraticRoots(a, h, c float64) ([2]float64, bool) {
+	discriminant := h*h - a*c
+	if discriminant < 0 {
+		return [2]float64{}, false
+	}
+	sqrtDiscriminant := math.Sqrt(discriminant)
+	t0, t1 := (-h-sqrtDiscriminant)/a, (-h+sqrtDiscriminant)/a
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+	return [2]float64{t0, t1}, true
+}
+
+// capHit 局部坐标下射线与 y=capY 平面上半径为r、圆心在Y轴上的圆盘求交，UV按x、z平铺
+func capHit(o, d Vec3, capY, r float64, rayT Interval) (hit bool, t, u, v float64) {
+	if math.Abs(d.Y) < 1e-12 {
+		return false, 0, 0, 0
+	}
+	t = (capY - o.Y) / d.Y
+	if !rayT.Surrounds(t) {
+		return false, 0, 0, 0
+	}
+	x, z := o.X+t*d.X, o.Z+t*d.Z
+	if x*x+z*z > r*r {
+		return false, 0, 0, 0
+	}
+	return true, t, (x/r + 1) / 2, (z/r + 1) / 2
+}
+
+// axialAABB 底面圆心为center、沿+Y轴高height、半径r的包围盒
+func axialAABB(center Point, r, height float64) *AABB {
+	return NewAABBFromPoints(
+		Point(Vec3(center).Sub(Vec3{X: r, Z: r})),
+		Point(Vec3(center).Add(Vec3{X: r, Y: height, Z: r})),
+	)
+}
@@ -0,0 +1,108 @@
+package core
+
+import "math"
+
+/*
+边缘保持的À-Trous小波降噪（Dammertz et al. 2010）：
+每次迭代使用5x5的B3样条核，采样间隔为2^i，逐次扩大感受野。
+每个邻居的权重由颜色、法线和反照率的差异共同决定，差异越大权重越小，从而在物体边缘和纹理处保持锐利。
+开启AOV时先用反照率对颜色做去调制（只降噪光照部分），最后再乘回反照率以保留纹理细节。
+*/
+
+// Denoiser 降噪参数
+type Denoiser struct {
+	Iterations  int     // 迭代次数，滤波半径约为2^Iterations像素
+	Strength    float64 // 降噪强度，缩放颜色容差，0为不降噪
+	ColorSigma  float64 // 颜色容差
+	NormalSigma float64 // 法线相似度指数，越大越敏感
+	AlbedoSigma float64 // 反照率容差
+}
+
+func NewDenoiser(strength float64) *Denoiser {
+	return &Denoiser{
+		Iterations:  5,
+		Strength:    strength,
+		ColorSigma:  0.5,
+		NormalSigma: 64,
+		AlbedoSigma: 0.1,
+	}
+}
+
+var aTrousKernel = [5]float64{1.0 / 16, 1.0 / 4, 3.0 / 8, 1.0 / 4, 1.0 / 16}
+
+// Denoise 对线性颜色缓冲降噪，aov为nil时只使用颜色作为边缘引导
+func (d *Denoiser) Denoise(colors []Color, width, height int, aov *AOVBuffer) []Color {
+	if d.Strength <= 0 || d.Iterations <= 0 {
+		return colors
+	}
+	const eps = 1e-3
+	current := make([]Color, len(colors))
+	copy(current, colors)
+	if aov != nil {
+		for i := range current {
+			a := aov.Albedo[i]
+			current[i] = Color{current[i].X / (a.X + eps), current[i].Y / (a.Y + eps), current[i].Z / (a.Z + eps)}
+		}
+	}
+	next := make([]Color, len(colors))
+	colorSigma := d.ColorSigma * d.Strength
+	for iteration := 0; iteration < d.Iterations; iteration++ {
+		step := 1 << iteration
+		// 随迭代缩小颜色容差，避免大尺度上过度模糊
+		sigma := colorSigma / float64(step)
+		invColor := 1 / (sigma * sigma)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p := y*width + x
+				var sum Vec3
+				weightSum := 0.0
+				for ky := -2; ky <= 2; ky++ {
+					qy := y + ky*step
+					if qy < 0 || qy >= height {
+						continue
+					}
+					for kx := -2; kx <= 2; kx++ {
+						qx := x + kx*step
+						if qx < 0 || qx >= width {
+							continue
+						}
+						q := qy*width + qx
+						w := aTrousKernel[kx+2] * aTrousKernel[ky+2]
+						w *= math.Exp(-Vec3(current[p]).Sub(Vec3(current[q])).LengthSquared() * invColor)
+						if aov != nil {
+							w *= d.guideWeight(aov, p, q)
+						}
+						sum = sum.Add(Vec3(current[q]).MultiplicationNum(w))
+						weightSum += w
+					}
+				}
+				if weightSum > 0 {
+					next[p] = Color(sum.Div(weightSum))
+				} else {
+					next[p] = current[p]
+				}
+			}
+		}
+		current, next = next, current
+	}
+	if aov != nil {
+		for i := range current {
+			a := aov.Albedo[i]
+			current[i] = Color{current[i].X * (a.X + eps), current[i].Y * (a.Y + eps), current[i].Z * (a.Z + eps)}
+		}
+	}
+	return current
+}
+
+// guideWeight 根据法线、反照率和是否击中物体计算边缘权重
+func (d *Denoiser) guideWeight(aov *AOVBuffer, p, q int) float64 {
+	if (aov.ObjectID[p] == 0) != (aov.ObjectID[q] == 0) {
+		return 0 // 物体与背景之间不混合
+	}
+	w := 1.0
+	if aov.ObjectID[p] != 0 {
+		w *= math.Pow(math.Max(0, aov.Normal[p].Dot(aov.Normal[q])), d.NormalSigma)
+	}
+	albedoDist := Vec3(aov.Albedo[p]).Sub(Vec3(aov.Albedo[q])).LengthSquared()
+	return w * math.Exp(-albedoDist/(d.AlbedoSigma*d.AlbedoSigma))
+}
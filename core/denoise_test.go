@@ -0,0 +1,56 @@
+package core
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDenoiserKeepsEdges(t *testing.T) {
+	const w, h = 32, 32
+	rng := rand.New(rand.NewSource(1))
+	aov := NewAOVBuffer(w, h)
+	colors := make([]Color, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// 左半边为暗面，右半边为亮面，法线不同
+			base, normal, id := 0.2, Vec3{1, 0, 0}, 1
+			if x >= w/2 {
+				base, normal, id = 0.8, Vec3{0, 1, 0}, 2
+			}
+			noise := (rng.Float64() - 0.5) * 0.2
+			colors[y*w+x] = Color{base + noise, base + noise, base + noise}
+			aov.Add(x, y, AOVSample{Hit: true, Albedo: Color{1, 1, 1}, Normal: normal, ObjectID: id, MaterialID: id})
+		}
+	}
+	aov.Resolve()
+	denoised := NewDenoiser(1).Denoise(colors, w, h, aov)
+
+	variance := func(c []Color, x0, x1 int, mean float64) float64 {
+		sum := 0.0
+		for y := 0; y < h; y++ {
+			for x := x0; x < x1; x++ {
+				d := c[y*w+x].X - mean
+				sum += d * d
+			}
+		}
+		return sum / float64(h*(x1-x0))
+	}
+	before, after := variance(colors, 0, w/2, 0.2), variance(denoised, 0, w/2, 0.2)
+	if after > before/4 {
+		t.Errorf("variance %v -> %v, expected strong reduction", before, after)
+	}
+	// 边缘两侧不应相互渗透
+	for y := 0; y < h; y++ {
+		if l, r := denoised[y*w+w/2-1].X, denoised[y*w+w/2].X; l > 0.35 || r < 0.65 {
+			t.Fatalf("edge blurred at row %d: %v | %v", y, l, r)
+		}
+	}
+}
+
+func TestDenoiserZeroStrength(t *testing.T) {
+	colors := []Color{{0.1, 0.2, 0.3}, {0.9, 0.8, 0.7}}
+	out := NewDenoiser(0).Denoise(colors, 2, 1, nil)
+	if out[0] != colors[0] || out[1] != colors[1] {
+		t.Errorf("zero strength changed the image: %v", out)
+	}
+}
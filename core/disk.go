@@ -0,0 +1,120 @@
+package core
+
+import "math"
+
+/*
+圆盘：圆心Center、法线Normal、半径Radius。
+与四边形一样先求射线与平面的交点，再判断交点到圆心的距离是否不超过半径。
+UV为极坐标：U为绕法线的角度，V为到圆心的距离，均归一化到[0,1]。
+*/
+type Disk struct {
+	Center   Point
+	Normal   Vec3 // 单位法线
+	Radius   float64
+	Material MaterialI
+	AABB     *AABB
+	u, v     Vec3    // 圆盘平面内的正交基，U从u方向开始计算
+	d        float64 // 平面方程中的常数D
+}
+
+func NewDisk(center Point, normal Vec3, radius float64) *Disk {
+	if math.Abs(normal.LengthSquared()-1) > 1e-12 {
+		// 已是单位向量时不再归一化，否则保存后重新加载时末位会因舍入而变化
+		normal = normal.Normalize()
+	}
+	u, v := orthonormalBasis(normal)
+	disk := &Disk{
+		Center: center,
+		Normal: normal,
+		Radius: radius,
+		u:      u,
+		v:      v,
+		d:      normal.Dot(Vec3(center)),
+	}
+	disk.SetBoundingBox(nil)
+	return disk
+}
+
+func (disk *Disk) WithMaterial(mat MaterialI) *Disk {
+	disk.Material = mat
+	return disk
+}
+
+func (disk *Disk) GetMaterial() MaterialI {
+	return disk.Material
+}
+
+// SetBoundingBox 传入nil时按圆盘在各轴上的投影计算：半径乘以 sqrt(1-n_i²)
+func (disk *Disk) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		disk.AABB = aabb
+		return
+	}
+	n := disk.Normal
+	extent := Vec3{
+		X: disk.Radius * math.Sqrt(math.Max(0, 1-n.X*n.X)),
+		Y: disk.Radius * math.Sqrt(math.Max(0, 1-n.Y*n.Y)),
+		Z: disk.Radius * math.Sqrt(math.Max(0, 1-n.Z*n.Z)),
+	}
+	disk.AABB = padAABB(NewAABBFromPoints(Point(Vec3(disk.Center).Sub(extent)), Point(Vec3(disk.Center).Add(extent))), 1e-4)
+}
+
+func (disk *Disk) GetBoundingBox() *AABB {
+	return disk.AABB
+}
+
+func (disk *Disk) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	denom := disk.Normal.Dot(ray.Direction)
+	// 射线与平面平行
+	if math.Abs(denom) < 1e-8 {
+		return false, hitRecord
+	}
+	t := (disk.d - disk.Normal.Dot(Vec3(ray.Origin))) / denom
+	if !rayT.Surrounds(t) {
+		return false, hitRecord
+	}
+	intersection := ray.At(t)
+	local := Vec3(intersection).Sub(Vec3(disk.Center))
+	distance := local.Length()
+	if distance > disk.Radius {
+		return false, hitRecord
+	}
+	hitRecord.Time = t
+	hitRecord.HitPoint = intersection
+	hitRecord.setFaceNormal(ray, disk.Normal)
+	hitRecord.U = angleUV(local.Dot(disk.v), local.Dot(disk.u))
+	hitRecord.V = distance / disk.Radius
+	hitRecord.Material = disk.Material
+	return true, hitRecord
+}
+
+// setFaceNormal 根据射线方向设置正反面，法线总是与射线方向相对
+func (hitRecord *HitRecord) setFaceNormal(ray Ray, outwardNormal Vec3) {
+	hitRecord.FrontFace = ray.Direction.Dot(outwardNormal) < 0
+	if hitRecord.FrontFace {
+		hitRecord.Normal = outwardNormal
+	} else {
+		hitRecord.Normal = outwardNormal.MultiplicationNum(-1.0)
+	}
+}
+
+// orthonormalBasis 返回与单位向量n正交的两个单位向量u、v，u×v=n
+func orthonormalBasis(n Vec3) (u, v Vec3) {
+	a := Vec3{X: 1}
+	if math.Abs(n.X) > 0.9 {
+		a = Vec3{Y: 1}
+	}
+	v = n.Cross(a).Normalize()
+	u = v.Cross(n)
+	return u, v
+}
+
+// angleUV 平面上点(x, y)的极角归一化到[0,1)，从+x轴开始逆时针
+func angleUV(y, x float64) float64 {
+	phi := math.Atan2(y, x)
+	if phi < 0 {
+		phi += 2 * math.Pi
+	}
+	return phi / (2 * math.Pi)
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+)
+
+// FilmSample 一个带图像坐标的采样结果，X,Y为连续像素坐标（像素(i,j)的中心为(i,j)）
+type FilmSample struct {
+	X, Y float64
+	C    Color
+	AOV  *AOVSample // 首次击中的辅助信息，未开启AOV时为nil
+}
+
+// Film 浮点帧缓冲，按重建滤波器累积加权采样
+type Film struct {
+	Width, Height int
+	Filter        FilterI
+	sum           []Color   // 加权颜色和
+	weight        []float64 // 权重和
+}
+
+func NewFilm(width, height int, filter FilterI) *Film {
+	if filter == nil {
+		filter = NewBoxFilter(0.5)
+	}
+	return &Film{
+		Width:  width,
+		Height: height,
+		Filter: filter,
+		sum:    make([]Color, width*height),
+		weight: make([]float64, width*height),
+	}
+}
+
+// AddSample 将采样按滤波权重泼溅到半径内的所有像素
+func (f *Film) AddSample(s FilmSample) {
+	r := f.Filter.Radius()
+	x0 := max(int(math.Ceil(s.X-r)), 0)
+	x1 := min(int(math.Floor(s.X+r)), f.Width-1)
+	y0 := max(int(math.Ceil(s.Y-r)), 0)
+	y1 := min(int(math.Floor(s.Y+r)), f.Height-1)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			w := f.Filter.Evaluate(s.X-float64(x), s.Y-float64(y))
+			if w == 0 {
+				continue
+			}
+			index := y*f.Width + x
+			f.sum[index] = Color(Vec3(f.sum[index]).Add(Vec3(s.C).MultiplicationNum(w)))
+			f.weight[index] += w
+		}
+	}
+}
+
+// At 返回像素(i,j)重建后的线性颜色，负瓣造成的负值截断为0
+func (f *Film) At(i, j int) Color {
+	index := j*f.Width + i
+	w := f.weight[index]
+	if w <= 0 {
+		return Color{}
+	}
+	c := Vec3(f.sum[index]).Div(w)
+	return Color{math.Max(c.X, 0), math.Max(c.Y, 0), math.Max(c.Z, 0)}
+}
+
+// Resolve 返回整幅图像的线性颜色（按行存储）
+func (f *Film) Resolve() []Color {
+	colors := make([]Color, f.Width*f.Height)
+	for j := 0; j < f.Height; j++ {
+		for i := 0; i < f.Width; i++ {
+			colors[j*f.Width+i] = f.At(i, j)
+		}
+	}
+	return colors
+}
+
+// Pixels 返回伽马校正后的像素
+func (f *Film) Pixels() []utils.Pixel {
+	return Colors2Pixels(f.Resolve())
+}
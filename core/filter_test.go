@@ -0,0 +1,53 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFilmBoxFilterAverage(t *testing.T) {
+	film := NewFilm(2, 1, NewBoxFilter(0.5))
+	film.AddSample(FilmSample{X: 0.2, Y: 0, C: Color{X: 1}})
+	film.AddSample(FilmSample{X: -0.3, Y: 0.1, C: Color{X: 0.5}})
+	film.AddSample(FilmSample{X: 1, Y: 0, C: Color{Z: 1}})
+	if c := film.At(0, 0); math.Abs(c.X-0.75) > 1e-9 || c.Z != 0 {
+		t.Fatalf("pixel (0,0) = %v, want {0.75 0 0}", c)
+	}
+	if c := film.At(1, 0); c.X != 0 || c.Z != 1 {
+		t.Fatalf("pixel (1,0) = %v, want {0 0 1}", c)
+	}
+}
+
+func TestFilmSplatsIntoNeighbours(t *testing.T) {
+	film := NewFilm(3, 3, NewTentFilter(1.5))
+	film.AddSample(FilmSample{X: 1, Y: 1, C: Color{1, 1, 1}})
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			if c := film.At(i, j); math.Abs(c.X-1) > 1e-9 {
+				t.Fatalf("pixel (%d,%d) = %v, want white", i, j, c)
+			}
+		}
+	}
+}
+
+func TestFilterPeakAtCenter(t *testing.T) {
+	for _, filterType := range []FilterType{BoxFilterType, TentFilterType, GaussianFilterType, MitchellFilterType, LanczosFilterType} {
+		filter, err := NewFilter(filterType, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		center := filter.Evaluate(0, 0)
+		if center <= 0 {
+			t.Errorf("%s: center weight %v <= 0", filterType, center)
+		}
+		if w := filter.Evaluate(filter.Radius()+0.1, 0); w != 0 {
+			t.Errorf("%s: weight outside radius = %v", filterType, w)
+		}
+		if w := filter.Evaluate(filter.Radius()/2, 0); w > center {
+			t.Errorf("%s: weight %v exceeds center weight %v", filterType, w, center)
+		}
+	}
+	if _, err := NewFilter("sinc", 1); err == nil {
+		t.Error("expected error for unknown filter")
+	}
+}
@@ -0,0 +1,278 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+)
+
+/*
+高度场地形：Width×Depth个采样点组成的规则网格，第i列、第j行的采样点位于
+(Origin.X + i/(Width-1)*Size.X, Origin.Y + h*Size.Y, Origin.Z + j/(Depth-1)*Size.Z)，
+h为高度值（灰度图为[0,1]，DEM数据可以直接给出米数并令Size.Y=1）。
+每个格子沿对角线分成两个三角形。求交时把射线投影到XZ平面，用二维DDA按顺序遍历射线经过的格子，
+射线在格子内的高度范围与格子的最低、最高点不重叠时直接跳过，否则与格子的两个三角形求交，
+第一个击中的格子就是最近的交点，不需要为每个三角形单独建BVH。
+法线由采样点处的中心差分法线按重心坐标插值得到，正反面按三角形的几何法线判断。
+UV覆盖整个高度场：U沿X轴，V沿Z轴反向，与图片从上到下的行一致，同一张图片作为纹理时正好对齐。
+*/
+type Heightfield struct {
+	Heights  []float64 // 按行存储的高度值，第j行第i列为 Heights[j*Width+i]
+	Width    int       // X方向采样点数
+	Depth    int       // Z方向采样点数
+	Origin   Point
+	Size     Vec3
+	Path     string // 灰度图路径，由 NewHeightfieldFromFile 设置，场景文件按路径保存高度场
+	Material MaterialI
+	AABB     *AABB
+	normals  []Vec3    // 采样点法线
+	cellMin  []float64 // 每个格子的最低点（世界坐标）
+	cellMax  []float64 // 每个格子的最高点（世界坐标）
+}
+
+func NewHeightfield(heights []float64, width, depth int, origin Point, size Vec3) (*Heightfield, error) {
+	if width < 2 || depth < 2 {
+		return nil, fmt.Errorf("heightfield needs at least 2x2 samples, got %dx%d", width, depth)
+	}
+	if len(heights) != width*depth {
+		return nil, fmt.Errorf("heightfield has %d samples, want %dx%d", len(heights), width, depth)
+	}
+	if size.X <= 0 || size.Z <= 0 {
+		return nil, fmt.Errorf("heightfield size must be positive in x and z, got %v", size)
+	}
+	field := &Heightfield{
+		Heights: heights,
+		Width:   width,
+		Depth:   depth,
+		Origin:  origin,
+		Size:    size,
+	}
+	field.precompute()
+	field.SetBoundingBox(nil)
+	return field, nil
+}
+
+// NewHeightfieldFromFile 读取灰度图（PNG、JPEG、PPM）作为高度场，见 NewHeightfieldFromImage
+func NewHeightfieldFromFile(path string, origin Point, size Vec3) (*Heightfield, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	field, err := NewHeightfieldFromImage(img, origin, size)
+	if err != nil {
+		return nil, err
+	}
+	field.Path = path
+	return field, nil
+}
+
+// NewHeightfieldFromImage 图片每个像素的亮度（线性，不做伽马校正）为[0,1]内的高度，图片的行沿+Z方向排列
+func NewHeightfieldFromImage(img image.Image, origin Point, size Vec3) (*Heightfield, error) {
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, errors.New("heightfield image is empty")
+	}
+	heights := make([]float64, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			heights = append(heights, float64(gray.Y)/0xffff)
+		}
+	}
+	return NewHeightfield(heights, bounds.Dx(), bounds.Dy(), origin, size)
+}
+
+func (field *Heightfield) WithMaterial(mat MaterialI) *Heightfield {
+	field.Material = mat
+	return field
+}
+
+func (field *Heightfield) GetMaterial() MaterialI {
+	return field.Material
+}
+
+// SetBoundingBox 传入nil时由网格范围和最低、最高点计算
+func (field *Heightfield) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		field.AABB = aabb
+		return
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for i := range field.cellMin {
+		low = math.Min(low, field.cellMin[i])
+		high = math.Max(high, field.cellMax[i])
+	}
+	field.AABB = padAABB(NewAABBFromPoints(
+		Point{X: field.Origin.X, Y: low, Z: field.Origin.Z},
+		Point{X: field.Origin.X + field.Size.X, Y: high, Z: field.Origin.Z + field.Size.Z},
+	), 1e-4)
+}
+
+func (field *Heightfield) GetBoundingBox() *AABB {
+	return field.AABB
+}
+
+// cellSize 格子在X、Z方向的边长
+func (field *Heightfield) cellSize() (float64, float64) {
+	return field.Size.X / float64(field.Width-1), field.Size.Z / float64(field.Depth-1)
+}
+
+// vertex 第i列第j行采样点的世界坐标
+func (field *Heightfield) vertex(i, j int) Vec3 {
+	dx, dz := field.cellSize()
+	return Vec3{
+		X: field.Origin.X + float64(i)*dx,
+		Y: field.Origin.Y + field.Heights[j*field.Width+i]*field.Size.Y,
+		Z: field.Origin.Z + float64(j)*dz,
+	}
+}
+
+// precompute 计算采样点法线和每个格子的高度范围
+func (field *Heightfield) precompute() {
+	w, d := field.Width, field.Depth
+	field.normals = make([]Vec3, w*d)
+	for j := range d {
+		for i := range w {
+			// 中心差分，边界处用单侧差分
+			left, right := max(i-1, 0), min(i+1, w-1)
+			back, front := max(j-1, 0), min(j+1, d-1)
+			slopeX := field.vertex(right, j).Sub(field.vertex(left, j))
+			slopeZ := field.vertex(i, front).Sub(field.vertex(i, back))
+			field.normals[j*w+i] = slopeZ.Cross(slopeX).Normalize()
+		}
+	}
+	field.cellMin = make([]float64, (w-1)*(d-1))
+	field.cellMax = make([]float64, (w-1)*(d-1))
+	for j := range d - 1 {
+		for i := range w - 1 {
+			low, high := math.Inf(1), math.Inf(-1)
+			for _, corner := range [4][2]int{{i, j}, {i + 1, j}, {i, j + 1}, {i + 1, j + 1}} {
+				y := field.vertex(corner[0], corner[1]).Y
+				low, high = math.Min(low, y), math.Max(high, y)
+			}
+			field.cellMin[j*(w-1)+i] = low
+			field.cellMax[j*(w-1)+i] = high
+		}
+	}
+}
+
+func (field *Heightfield) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	boxT, inBox := field.AABB.Clip(&ray, rayT)
+	if !inBox {
+		return false, hitRecord
+	}
+	cellX, cellZ := field.cellSize()
+	cols, rows := field.Width-1, field.Depth-1
+	// 射线在网格坐标（以格子为单位）中的起点与方向
+	start := ray.At(boxT.Min)
+	gx := (start.X - field.Origin.X) / cellX
+	gz := (start.Z - field.Origin.Z) / cellZ
+	dx := ray.Direction.X / cellX
+	dz := ray.Direction.Z / cellZ
+	i := min(max(int(math.Floor(gx)), 0), cols-1)
+	j := min(max(int(math.Floor(gz)), 0), rows-1)
+
+	// DDA：tNext为射线到达下一条格线的参数，tDelta为穿过一个格子的参数增量
+	stepI, tNextI, tDeltaI := ddaAxis(gx, dx, i, boxT.Min)
+	stepJ, tNextJ, tDeltaJ := ddaAxis(gz, dz, j, boxT.Min)
+	tEnter := boxT.Min
+	for i >= 0 && i < cols && j >= 0 && j < rows && tEnter < boxT.Max {
+		tExit := math.Min(math.Min(tNextI, tNextJ), boxT.Max)
+		// 射线在格子内的高度范围与格子的高度范围重叠时才需要与三角形求交
+		y0, y1 := ray.At(tEnter).Y, ray.At(tExit).Y
+		cell := j*cols + i
+		if math.Max(y0, y1) >= field.cellMin[cell] && math.Min(y0, y1) <= field.cellMax[cell] {
+			if hit, hitRecord = field.hitCell(ray, i, j, rayT); hit {
+				return true, hitRecord
+			}
+		}
+		tEnter = tExit
+		if tNextI < tNextJ {
+			i += stepI
+			tNextI += tDeltaI
+		} else {
+			j += stepJ
+			tNextJ += tDeltaJ
+		}
+	}
+	return false, hitRecord
+}
+
+// ddaAxis 单个轴上的DDA参数：前进方向、到达下一条格线的射线参数、穿过一个格子的参数增量
+func ddaAxis(g, d float64, cell int, t0 float64) (step int, tNext, tDelta float64) {
+	switch {
+	case d > 0:
+		return 1, t0 + (float64(cell+1)-g)/d, 1 / d
+	case d < 0:
+		return -1, t0 + (float64(cell)-g)/d, -1 / d
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+// hitCell 与第i列第j行格子的两个三角形求交，返回较近的交点
+func (field *Heightfield) hitCell(ray Ray, i, j int, rayT Interval) (hit bool, hitRecord HitRecord) {
+	corners := [4][2]int{{i, j}, {i + 1, j}, {i + 1, j + 1}, {i, j + 1}}
+	for _, triangle := range [2][3]int{{0, 1, 2}, {0, 2, 3}} {
+		var vertices, normals [3]Vec3
+		for k, c := range triangle {
+			vertices[k] = field.vertex(corners[c][0], corners[c][1])
+			normals[k] = field.normals[corners[c][1]*field.Width+corners[c][0]]
+		}
+		t, b1, b2, ok := hitTriangle(ray, vertices)
+		if !ok || !rayT.Surrounds(t) {
+			continue
+		}
+		hit = true
+		rayT.Max = t
+		hitRecord.Time = t
+		hitRecord.HitPoint = ray.At(t)
+		geometric := vertices[2].Sub(vertices[0]).Cross(vertices[1].Sub(vertices[0]))
+		if geometric.Y < 0 {
+			geometric = geometric.MultiplicationNum(-1)
+		}
+		shading := normals[0].MultiplicationNum(1 - b1 - b2).Add(normals[1].MultiplicationNum(b1)).Add(normals[2].MultiplicationNum(b2)).Normalize()
+		hitRecord.FrontFace = ray.Direction.Dot(geometric) < 0
+		if hitRecord.FrontFace {
+			hitRecord.Normal = shading
+		} else {
+			hitRecord.Normal = shading.MultiplicationNum(-1)
+		}
+		hitRecord.U = (hitRecord.HitPoint.X - field.Origin.X) / field.Size.X
+		hitRecord.V = 1 - (hitRecord.HitPoint.Z-field.Origin.Z)/field.Size.Z
+		hitRecord.Material = field.Material
+	}
+	return hit, hitRecord
+}
+
+// hitTriangle Möller–Trumbore 射线与三角形求交，返回射线参数和重心坐标（对应第二、第三个顶点）
+func hitTriangle(ray Ray, vertices [3]Vec3) (t, b1, b2 float64, hit bool) {
+	edge1 := vertices[1].Sub(vertices[0])
+	edge2 := vertices[2].Sub(vertices[0])
+	p := ray.Direction.Cross(edge2)
+	det := edge1.Dot(p)
+	if math.Abs(det) < 1e-12 {
+		return 0, 0, 0, false
+	}
+	inv := 1 / det
+	s := Vec3(ray.Origin).Sub(vertices[0])
+	b1 = s.Dot(p) * inv
+	if b1 < 0 || b1 > 1 {
+		return 0, 0, 0, false
+	}
+	q := s.Cross(edge1)
+	b2 = ray.Direction.Dot(q) * inv
+	if b2 < 0 || b1+b2 > 1 {
+		return 0, 0, 0, false
+	}
+	return edge2.Dot(q) * inv, b1, b2, true
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeightfield(t *testing.T) {
+	// 沿X方向的斜坡 y = x/2
+	ramp := make([]float64, 5*3)
+	for j := range 3 {
+		for i := range 5 {
+			ramp[j*5+i] = float64(i) / 4
+		}
+	}
+	field, err := NewHeightfield(ramp, 5, 3, Point{}, Vec3{X: 4, Y: 2, Z: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if box := field.GetBoundingBox(); box.X != (Interval{0, 4}) || box.Y != (Interval{0, 2}) || box.Z != (Interval{0, 2}) {
+		t.Errorf("bounding box %+v", box)
+	}
+	slope := Vec3{X: -0.5, Y: 1}.Normalize()
+	checkPrimitiveHits(t, field, []primitiveHitTest{
+		{"from above", NewRay(Point{X: 1, Y: 5, Z: 0.5}, Vec3{Y: -1}), true, 4.5, slope, true},
+		{"from below", NewRay(Point{X: 3, Y: -1, Z: 1.5}, Vec3{Y: 1}), true, 2.5, slope.MultiplicationNum(-1), false},
+		{"across", NewRay(Point{X: -1, Y: 1, Z: 1}, Vec3{X: 1}), true, 3, slope, true},
+		{"above the slope", NewRay(Point{X: -1, Y: 2.5, Z: 1}, Vec3{X: 1}), false, 0, Vec3{}, false},
+		{"outside", NewRay(Point{X: 5, Y: 5, Z: 1}, Vec3{Y: -1}), false, 0, Vec3{}, false},
+	})
+	_, record := field.Hittable(NewRay(Point{X: 1, Y: 5, Z: 0.5}, Vec3{Y: -1}), NewInterval(1e-5, math.Inf(1)))
+	if math.Abs(record.U-0.25) > 1e-9 || math.Abs(record.V-0.75) > 1e-9 {
+		t.Errorf("uv = (%v, %v)", record.U, record.V)
+	}
+
+	if _, err := NewHeightfield(ramp, 4, 3, Point{}, Vec3{X: 1, Y: 1, Z: 1}); err == nil {
+		t.Error("expected an error for a sample count mismatch")
+	}
+	if _, err := NewHeightfield([]float64{0}, 1, 1, Point{}, Vec3{X: 1, Y: 1, Z: 1}); err == nil {
+		t.Error("expected an error for a 1x1 grid")
+	}
+}
+
+// DDA遍历的结果必须与逐个三角形求交的结果一致
+func TestHeightfieldTraversal(t *testing.T) {
+	const width, depth = 9, 7
+	heights := make([]float64, width*depth)
+	for k := range heights {
+		heights[k] = 0.5 + 0.5*math.Sin(float64(k)*1.7)*math.Cos(float64(k)*0.3)
+	}
+	field, err := NewHeightfield(heights, width, depth, Point{X: -2, Y: -0.5, Z: 1}, Vec3{X: 4, Y: 1.5, Z: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bruteForce := func(ray Ray) (bool, float64) {
+		hit, closest := false, math.Inf(1)
+		for j := range depth - 1 {
+			for i := range width - 1 {
+				if ok, record := field.hitCell(ray, i, j, NewInterval(1e-5, closest)); ok {
+					hit, closest = true, record.Time
+				}
+			}
+		}
+		return hit, closest
+	}
+	rays := 0
+	for a := range 12 {
+		for b := range 12 {
+			origin := Point{X: -4 + float64(a)*0.7, Y: 3, Z: -1 + float64(b)*0.5}
+			target := Point{X: 3 - float64(b)*0.55, Y: -0.2 + 0.1*float64(a%5), Z: 4.5 - float64(a)*0.3}
+			ray := NewRay(origin, Vec3(target).Sub(Vec3(origin)))
+			wantHit, wantT := bruteForce(ray)
+			hit, record := field.Hittable(ray, NewInterval(1e-5, math.Inf(1)))
+			if hit != wantHit || (hit && math.Abs(record.Time-wantT) > 1e-9) {
+				t.Errorf("ray %v: hit %v t=%v, brute force %v t=%v", ray, hit, record.Time, wantHit, wantT)
+			}
+			if hit {
+				rays++
+			}
+		}
+	}
+	if rays == 0 {
+		t.Error("no test ray hit the terrain")
+	}
+}
+
+func TestHeightfieldFromFile(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 4, 3))
+	img.SetGray(2, 1, color.Gray{Y: 255})
+	path := filepath.Join(t.TempDir(), "height.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	field, err := NewHeightfieldFromFile(path, Point{}, Vec3{X: 3, Y: 2, Z: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if field.Width != 4 || field.Depth != 3 || field.Heights[1*4+2] != 1 || field.GetBoundingBox().Y.Max < 2 {
+		t.Errorf("field %dx%d, peak %v", field.Width, field.Depth, field.Heights[1*4+2])
+	}
+	// 峰顶处法线向上
+	if hit, record := field.Hittable(NewRay(Point{X: 2, Y: 5, Z: 1}, Vec3{Y: -1}), NewInterval(1e-5, math.Inf(1))); !hit || math.Abs(record.Time-3) > 1e-9 || !vecNear(record.Normal, Vec3{Y: 1}) {
+		t.Errorf("peak hit %v t=%v normal=%v", hit, record.Time, record.Normal)
+	}
+	if _, err := NewHeightfieldFromFile(filepath.Join(t.TempDir(), "missing.png"), Point{}, Vec3{X: 1, Y: 1, Z: 1}); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -9,15 +9,19 @@ type RemovableI interface {
 type RemovableSetting struct {
 	MovingFunc  func(t float64) Point
 	IsRemovable bool
+	End         *Point  // 匀速直线运动的终点，自定义MovingFunc时为nil
+	StartTime   float64 // 匀速直线运动开始的时刻
+	EndTime     float64 // 匀速直线运动结束的时刻
 }
 
 type HitRecord struct {
-	Time      float64   // 负根
-	HitPoint  Point     // 交叉点
-	Material  MaterialI // 击中点材质
-	Normal    Vec3      // 交叉点法线
-	FrontFace bool      // 法线方向
-	U, V      float64   // UV坐标
+	Time      float64       // 负根
+	HitPoint  Point         // 交叉点
+	Material  MaterialI     // 击中点材质
+	Normal    Vec3          // 交叉点法线
+	FrontFace bool          // 法线方向
+	U, V      float64       // UV坐标
+	Object    HittableItemI // 击中的场景顶层物体
 }
 
 type HittableItemI interface {
@@ -28,9 +32,10 @@ type HittableItemI interface {
 
 type Scenes struct {
 	HittableList []HittableItemI
-	HittableAABB *AABB
+	HittableAABB *AABB // 有界物体的包围盒
 	EnabledBVH   bool
-	BVH          *BVHNode
+	BVH          *BVHNode        // 有界物体的层次结构，没有有界物体时为nil
+	Unbounded    []HittableItemI // 不放入BVH、逐个求交的无界物体
 }
 
 func (s *Scenes) Len() int {
@@ -40,7 +45,26 @@ func (s *Scenes) Len() int {
 func (s *Scenes) Add(items ...HittableItemI) {
 	for _, item := range items {
 		s.HittableList = append(s.HittableList, item)
-		s.HittableAABB = NewAABBFromAABB(s.HittableAABB, item.GetBoundingBox())
+		if item.GetBoundingBox().IsBounded() {
+			s.HittableAABB = NewAABBFromAABB(s.HittableAABB, item.GetBoundingBox())
+		}
+	}
+}
+
+// BuildBVH 为有界物体构建BVH，无界物体（如无限平面）会使包围盒无穷大、无法按最长轴划分，单独放在Unbounded中
+func (s *Scenes) BuildBVH() {
+	var bounded []HittableItemI
+	s.Unbounded = nil
+	for _, item := range s.HittableList {
+		if item.GetBoundingBox().IsBounded() {
+			bounded = append(bounded, item)
+		} else {
+			s.Unbounded = append(s.Unbounded, item)
+		}
+	}
+	s.BVH = nil
+	if len(bounded) > 0 {
+		s.BVH = NewBVHNode(bounded)
 	}
 }
 
@@ -48,6 +72,32 @@ func (s *Scenes) GetBoundingBox() *AABB {
 	return s.HittableAABB
 }
 
+// Hit 根据是否开启BVH选择求交方式
+func (s *Scenes) Hit(ray Ray, rayT Interval) (bool, HitRecord) {
+	if s.EnabledBVH {
+		return s.hitBVH(ray, rayT)
+	}
+	return s.HitAnything(ray, rayT)
+}
+
+// hitBVH 先与BVH求交，再用最近的击中时间收窄区间与无界物体求交
+func (s *Scenes) hitBVH(ray Ray, rayT Interval) (hitAnything bool, record HitRecord) {
+	if s.BVH != nil {
+		if hitAnything, record = s.BVH.Hittable(ray, rayT); hitAnything {
+			rayT.Max = record.Time
+		}
+	}
+	for _, item := range s.Unbounded {
+		if hit, hitRecord := item.Hittable(ray, rayT); hit {
+			hitAnything = true
+			rayT.Max = hitRecord.Time
+			record = hitRecord
+			record.Object = item
+		}
+	}
+	return hitAnything, record
+}
+
 func (s *Scenes) HitAnything(ray Ray, rayT Interval) (hitAnything bool, record HitRecord) {
 	closestSoFar := rayT.Max
 	for _, hittableItem := range s.HittableList {
@@ -58,6 +108,7 @@ func (s *Scenes) HitAnything(ray Ray, rayT Interval) (hitAnything bool, record H
 				hitAnything = true
 				closestSoFar = hitRecord.Time
 				record = hitRecord
+				record.Object = hittableItem
 			}
 		} else {
 			rayT.Max = closestSoFar
@@ -127,11 +178,19 @@ func (sphere *Sphere) WithMaterial(mat MaterialI) *Sphere {
 	return sphere
 }
 
+func (sphere *Sphere) GetMaterial() MaterialI {
+	return sphere.Material
+}
+
 func (sphere *Sphere) SetBoundingBox(aabb *AABB) {
 	if sphere.RemovableSetting.IsRemovable {
 		origin := sphere.Center
 		radius := sphere.Radius
+		// 匀速直线运动覆盖起点到终点，自定义运动函数按t∈[0,1]计算
 		end := sphere.NowAt(1.0)
+		if sphere.RemovableSetting.End != nil {
+			end = *sphere.RemovableSetting.End
+		}
 		aabbOrigin := NewAABBFromPoints(Point(Vec3(origin).Sub(Vec3{radius, radius, radius})), Point(Vec3(origin).Add(Vec3{radius, radius, radius})))
 		aabbEnd := NewAABBFromPoints(Point(Vec3(end).Sub(Vec3{radius, radius, radius})), Point(Vec3(end).Add(Vec3{radius, radius, radius})))
 		sphere.AABB = NewAABBFromAABB(aabbOrigin, aabbEnd)
@@ -154,20 +213,33 @@ func (sphere *Sphere) NowAt(time float64) Point {
 	}
 }
 
-// SetUniformLinearMovement 设置球体匀速直线运动
+// SetUniformLinearMovement 设置球体匀速直线运动，t=0时位于Center，t=1时到达end，包围盒随之覆盖整段运动
 func (sphere *Sphere) SetUniformLinearMovement(end Point) {
+	sphere.SetLinearMovement(end, 0, 1)
+}
+
+// SetLinearMovement 设置球体在[startTime, endTime]内从Center匀速运动到end。
+// 这段时间之前停在Center、之后停在end，因此快门设置在任何时间段（如动画的第n帧）时球体都不会离开包围盒
+func (sphere *Sphere) SetLinearMovement(end Point, startTime, endTime float64) {
 	sphere.RemovableSetting.IsRemovable = true
+	sphere.RemovableSetting.End = &end
+	sphere.RemovableSetting.StartTime = startTime
+	sphere.RemovableSetting.EndTime = endTime
 	sphere.RemovableSetting.MovingFunc = func(t float64) Point {
-		moveRay := Ray{
-			Origin:    sphere.Center,
-			Direction: Vec3(end).Sub(Vec3(sphere.Center)).Normalize(),
-			TM:        t,
+		var s float64
+		switch {
+		case t >= endTime:
+			s = 1
+		case t > startTime:
+			s = (t - startTime) / (endTime - startTime)
 		}
-		return moveRay.At(t)
+		return Point(lerpVec3(Vec3(sphere.Center), Vec3(end), math.Max(0, math.Min(1, s))))
 	}
+	sphere.SetBoundingBox(sphere.AABB)
 }
 
 func (sphere *Sphere) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
 	currentCenter := sphere.NowAt(ray.TM)
 
 	var oc = Vec3(currentCenter).Sub(Vec3(ray.Origin))
@@ -200,7 +272,15 @@ func (sphere *Sphere) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitR
 		hitRecord.FrontFace = false
 		hitRecord.Normal = outwardNormal.MultiplicationNum(-1.0)
 	}
+	hitRecord.U, hitRecord.V = sphereUV(outwardNormal)
 	// 记录击中位置的材质
 	hitRecord.Material = sphere.Material
 	return true, hitRecord
 }
+
+// sphereUV 单位球面上的点p对应的UV：U为绕Y轴的角度（从-X开始），V为从-Y到+Y的角度，均归一化到[0,1]
+func sphereUV(p Vec3) (u, v float64) {
+	theta := math.Acos(math.Max(-1, math.Min(1, -p.Y)))
+	phi := math.Atan2(-p.Z, p.X) + math.Pi
+	return phi / (2 * math.Pi), theta / math.Pi
+}
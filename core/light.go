@@ -0,0 +1,29 @@
+package core
+
+/*
+光源：
+自发光材质不散射光线，只在被击中时返回自身发出的颜色，颜色分量可以大于1。
+没有天空光照的场景（如Cornell盒子）需要把相机的BackgroundColor设为黑色，只靠光源照亮。
+*/
+
+// EmitterI 自发光材质接口
+type EmitterI interface {
+	Emitted(u, v float64, p Point) Color
+}
+
+// DiffuseLight 漫射光源，向各个方向均匀发光
+type DiffuseLight struct {
+	Tex TextureI
+}
+
+func NewDiffuseLight(emit Color) DiffuseLight {
+	return DiffuseLight{Tex: NewSolidColorTexture(emit)}
+}
+
+func (d DiffuseLight) Scatter(r *Ray, h HitRecord) (hit bool, attenuation Color, scattered *Ray) {
+	return false, Color{}, nil
+}
+
+func (d DiffuseLight) Emitted(u, v float64, p Point) Color {
+	return d.Tex.Value(u, v, p)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+)
+
+// Mat3 3x3矩阵，按行存储
+type Mat3 [3][3]float64
+
+func IdentityMat3() Mat3 {
+	return Mat3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+}
+
+// RotationMat3 由欧拉角（度）构造旋转矩阵，依次绕X、Y、Z轴旋转
+func RotationMat3(degrees Vec3) Mat3 {
+	x, y, z := utils.Degrees2Radians(degrees.X), utils.Degrees2Radians(degrees.Y), utils.Degrees2Radians(degrees.Z)
+	rx := Mat3{{1, 0, 0}, {0, math.Cos(x), -math.Sin(x)}, {0, math.Sin(x), math.Cos(x)}}
+	ry := Mat3{{math.Cos(y), 0, math.Sin(y)}, {0, 1, 0}, {-math.Sin(y), 0, math.Cos(y)}}
+	rz := Mat3{{math.Cos(z), -math.Sin(z), 0}, {math.Sin(z), math.Cos(z), 0}, {0, 0, 1}}
+	return rz.Mul(ry).Mul(rx)
+}
+
+// Mul 矩阵乘法 m*o
+func (m Mat3) Mul(o Mat3) Mat3 {
+	var r Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[i][0]*o[0][j] + m[i][1]*o[1][j] + m[i][2]*o[2][j]
+		}
+	}
+	return r
+}
+
+// MulVec3 矩阵乘向量 m*v
+func (m Mat3) MulVec3(v Vec3) Vec3 {
+	return Vec3{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// Transpose 转置，旋转矩阵的转置即其逆
+func (m Mat3) Transpose() Mat3 {
+	var r Mat3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+	return r
+}
+
+// EulerDegrees 将旋转矩阵分解回欧拉角（度），与RotationMat3互逆；万向节锁时令X为0
+func (m Mat3) EulerDegrees() Vec3 {
+	toDegrees := func(r float64) float64 { return r * 180 / utils.PI }
+	sy := math.Max(-1, math.Min(1, -m[2][0]))
+	y := math.Asin(sy)
+	if math.Abs(sy) > 1-1e-9 {
+		return Vec3{0, toDegrees(y), toDegrees(math.Atan2(-m[0][1], m[1][1]))}
+	}
+	return Vec3{
+		X: toDegrees(math.Atan2(m[2][1], m[2][2])),
+		Y: toDegrees(y),
+		Z: toDegrees(math.Atan2(m[1][0], m[0][0])),
+	}
+}
@@ -57,12 +57,19 @@ type LambertianReflectionMaterial struct {
 	Tex    TextureI // 材质
 }
 
+func (l LambertianReflectionMaterial) AlbedoAt(h HitRecord) Color {
+	if l.Tex != nil {
+		return l.Tex.Value(h.U, h.V, h.HitPoint)
+	}
+	return l.Albedo
+}
+
 func (l LambertianReflectionMaterial) Scatter(r *Ray, hitRecord HitRecord) (hit bool, attenuation Color, scattered *Ray) {
-	scatterDirection := hitRecord.Normal.Add(RandomNormalizedVec3())
+	scatterDirection := hitRecord.Normal.Add(RandomNormalizedVec3(r.rng))
 	if scatterDirection.NearZero() {
 		scatterDirection = hitRecord.Normal
 	}
-	scattered = &Ray{hitRecord.HitPoint, scatterDirection, r.Time()}
+	scattered = &Ray{Origin: hitRecord.HitPoint, Direction: scatterDirection, TM: r.Time()}
 
 	//
 	if l.Tex != nil {
@@ -87,10 +94,14 @@ type MetalMaterial struct {
 	Fuzz   float64 // 模糊度
 }
 
+func (m MetalMaterial) AlbedoAt(h HitRecord) Color {
+	return m.Albedo
+}
+
 func (m MetalMaterial) Scatter(r *Ray, h HitRecord) (hit bool, attenuation Color, scattered *Ray) {
 	directReflected := r.Direction.Reflect(h.Normal) // 反射光线方向
-	reflected := directReflected.Normalize().Add(RandomNormalizedVec3().MultiplicationNum(m.Fuzz))
-	scattered = &Ray{h.HitPoint, reflected, r.Time()} // 反射光线
+	reflected := directReflected.Normalize().Add(RandomNormalizedVec3(r.rng).MultiplicationNum(m.Fuzz))
+	scattered = &Ray{Origin: h.HitPoint, Direction: reflected, TM: r.Time()} // 反射光线
 	attenuation = m.Albedo
 	hit = scattered.Direction.Dot(h.Normal) > 0 // 判断是否反射光线与入射点法线同向，否的话无法进行下次
 	return hit, attenuation, scattered
@@ -103,6 +114,10 @@ type DielectricMaterial struct {
 	RefractionIndex float64 //  真空或空气中的折射率，或材料的折射率与封闭介质的折射率之比
 }
 
+func (d DielectricMaterial) AlbedoAt(h HitRecord) Color {
+	return Color{1.0, 1.0, 1.0}
+}
+
 func (d DielectricMaterial) Scatter(r *Ray, h HitRecord) (hit bool, attenuation Color, scattered *Ray) {
 	attenuation = Color{1.0, 1.0, 1.0}
 	ri := d.RefractionIndex
@@ -116,14 +131,14 @@ func (d DielectricMaterial) Scatter(r *Ray, h HitRecord) (hit bool, attenuation
 	cannotRefract := ri*sinTheta > 1.0
 	var refractedDirection Vec3
 	// 是否在里面
-	if cannotRefract || Reflectance(cosTheta, ri) > utils.Random() {
+	if cannotRefract || Reflectance(cosTheta, ri) > utils.RandomFrom(r.rng) {
 		// 折射
 		refractedDirection = rayInDirectionNormalized.Reflect(h.Normal)
 	} else {
 		// 折射
 		refractedDirection = rayInDirectionNormalized.Refract(h.Normal, ri) // 折射线方向
 	}
-	scattered = &Ray{h.HitPoint, refractedDirection, r.Time()} //折射或反射线
+	scattered = &Ray{Origin: h.HitPoint, Direction: refractedDirection, TM: r.Time()} //折射或反射线
 	return true, attenuation, scattered
 }
 
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+)
+
+/*
+恒定密度的参与介质（烟、雾）：
+射线进入边界后，在单位长度内发生散射的概率与密度成正比，因此散射前走过的距离服从指数分布：
+distance = -ln(ξ)/density。若这段距离小于射线在边界内的长度，就在该处散射，否则穿过介质。
+边界必须是封闭的凸体（如球、盒子），以便用两次求交得到射线进入和离开的位置。
+*/
+type ConstantMedium struct {
+	Boundary      HittableItemI
+	NegInvDensity float64   // -1/density
+	PhaseFunction MaterialI // 散射相函数
+}
+
+func NewConstantMedium(boundary HittableItemI, density float64, albedo Color) *ConstantMedium {
+	return NewConstantMediumWithTexture(boundary, density, NewSolidColorTexture(albedo))
+}
+
+func NewConstantMediumWithTexture(boundary HittableItemI, density float64, tex TextureI) *ConstantMedium {
+	return &ConstantMedium{
+		Boundary:      boundary,
+		NegInvDensity: -1 / density,
+		PhaseFunction: IsotropicMaterial{Tex: tex},
+	}
+}
+
+func (m *ConstantMedium) GetMaterial() MaterialI {
+	return m.PhaseFunction
+}
+
+func (m *ConstantMedium) Children() []HittableItemI {
+	return []HittableItemI{m.Boundary}
+}
+
+func (m *ConstantMedium) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	// 射线所在直线进入和离开边界的位置，起点可能已在介质内部
+	hit1, record1 := m.Boundary.Hittable(ray, NewUniverseInterval())
+	if !hit1 {
+		return false, hitRecord
+	}
+	hit2, record2 := m.Boundary.Hittable(ray, NewInterval(record1.Time+0.0001, utils.Infinity))
+	if !hit2 {
+		return false, hitRecord
+	}
+	t1, t2 := math.Max(record1.Time, rayT.Min), math.Min(record2.Time, rayT.Max)
+	if t1 >= t2 {
+		return false, hitRecord
+	}
+	t1 = math.Max(t1, 0)
+	rayLength := ray.Direction.Length()
+	distanceInsideBoundary := (t2 - t1) * rayLength
+	hitDistance := m.NegInvDensity * math.Log(utils.RandomFrom(ray.rng))
+	if hitDistance > distanceInsideBoundary {
+		return false, hitRecord
+	}
+	hitRecord.Time = t1 + hitDistance/rayLength
+	hitRecord.HitPoint = ray.At(hitRecord.Time)
+	hitRecord.Normal = Vec3{1, 0, 0} // 任意值，各向同性散射不使用法线
+	hitRecord.FrontFace = true
+	hitRecord.Material = m.PhaseFunction
+	return true, hitRecord
+}
+
+func (m *ConstantMedium) SetBoundingBox(aabb *AABB) {
+	m.Boundary.SetBoundingBox(aabb)
+}
+
+func (m *ConstantMedium) GetBoundingBox() *AABB {
+	return m.Boundary.GetBoundingBox()
+}
+
+// IsotropicMaterial 各向同性散射，向所有方向均匀散射
+type IsotropicMaterial struct {
+	Tex TextureI
+}
+
+func (i IsotropicMaterial) AlbedoAt(h HitRecord) Color {
+	return i.Tex.Value(h.U, h.V, h.HitPoint)
+}
+
+func (i IsotropicMaterial) Scatter(r *Ray, h HitRecord) (hit bool, attenuation Color, scattered *Ray) {
+	scattered = &Ray{Origin: h.HitPoint, Direction: RandomNormalizedVec3(r.rng), TM: r.Time()}
+	return true, i.Tex.Value(h.U, h.V, h.HitPoint), scattered
+}
@@ -0,0 +1,25 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConstantMedium(t *testing.T) {
+	// 密度极大的介质几乎在进入边界处立即散射，密度极小的介质几乎总被穿过
+	boundary := NewSphere(Point{Z: -5}, 1)
+	thick := NewConstantMedium(boundary, 1e6, Color{X: 1, Y: 1, Z: 1})
+	hit, record := thick.Hittable(NewRay(Point{}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1)))
+	if !hit || math.Abs(record.Time-4) > 1e-3 {
+		t.Errorf("thick medium: hit=%v t=%v", hit, record.Time)
+	}
+	thin := NewConstantMedium(boundary, 1e-9, Color{X: 1, Y: 1, Z: 1})
+	if hit, _ := thin.Hittable(NewRay(Point{}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1))); hit {
+		t.Error("thin medium scattered")
+	}
+	// 起点在介质内部
+	hit, record = thick.Hittable(NewRay(Point{Z: -5}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1)))
+	if !hit || record.Time > 1e-3 {
+		t.Errorf("inside thick medium: hit=%v t=%v", hit, record.Time)
+	}
+}
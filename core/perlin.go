@@ -0,0 +1,96 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"math"
+)
+
+/*
+Perlin噪声：
+在整数格点上放置随机单位向量，采样点的值为周围8个格点的向量与“格点->采样点”向量点积的三线性插值，
+插值权重经过Hermite平滑，避免格点处出现明显的网格痕迹。
+格点到随机向量的映射通过三个轴各自打乱的排列表异或得到。
+*/
+
+const perlinPointCount = 256
+
+type Perlin struct {
+	randVec             [perlinPointCount]Vec3
+	permX, permY, permZ [perlinPointCount]int
+}
+
+// NewPerlin 使用全局随机数生成，设置种子后结果可复现
+func NewPerlin() *Perlin {
+	p := &Perlin{}
+	for i := range p.randVec {
+		p.randVec[i] = RandomBetween(-1, 1).Normalize()
+	}
+	p.permX = perlinGeneratePerm()
+	p.permY = perlinGeneratePerm()
+	p.permZ = perlinGeneratePerm()
+	return p
+}
+
+func perlinGeneratePerm() (perm [perlinPointCount]int) {
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := perlinPointCount - 1; i > 0; i-- {
+		target := utils.RandomInt(0, i+1)
+		perm[i], perm[target] = perm[target], perm[i]
+	}
+	return perm
+}
+
+// Noise 取值范围约为[-1,1]
+func (p *Perlin) Noise(point Point) float64 {
+	u := point.X - math.Floor(point.X)
+	v := point.Y - math.Floor(point.Y)
+	w := point.Z - math.Floor(point.Z)
+	i := int(math.Floor(point.X))
+	j := int(math.Floor(point.Y))
+	k := int(math.Floor(point.Z))
+
+	var c [2][2][2]Vec3
+	for di := 0; di < 2; di++ {
+		for dj := 0; dj < 2; dj++ {
+			for dk := 0; dk < 2; dk++ {
+				c[di][dj][dk] = p.randVec[p.permX[(i+di)&255]^p.permY[(j+dj)&255]^p.permZ[(k+dk)&255]]
+			}
+		}
+	}
+	return perlinInterp(c, u, v, w)
+}
+
+func perlinInterp(c [2][2][2]Vec3, u, v, w float64) float64 {
+	uu := u * u * (3 - 2*u)
+	vv := v * v * (3 - 2*v)
+	ww := w * w * (3 - 2*w)
+	accum := 0.0
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			for k := 0; k < 2; k++ {
+				fi, fj, fk := float64(i), float64(j), float64(k)
+				weight := Vec3{u - fi, v - fj, w - fk}
+				accum += (fi*uu + (1-fi)*(1-uu)) *
+					(fj*vv + (1-fj)*(1-vv)) *
+					(fk*ww + (1-fk)*(1-ww)) *
+					c[i][j][k].Dot(weight)
+			}
+		}
+	}
+	return accum
+}
+
+// Turbulence 多个频率的噪声叠加（每层频率加倍、权重减半）后取绝对值
+func (p *Perlin) Turbulence(point Point, depth int) float64 {
+	accum := 0.0
+	temp := Vec3(point)
+	weight := 1.0
+	for i := 0; i < depth; i++ {
+		accum += weight * p.Noise(Point(temp))
+		weight *= 0.5
+		temp = temp.MultiplicationNum(2)
+	}
+	return math.Abs(accum)
+}
@@ -0,0 +1,88 @@
+package core
+
+import "math"
+
+/*
+无限平面：经过点Point、法线为Normal的平面 n·P = D。
+求交与四边形相同，只是不需要判断交点是否落在边界内。
+UV为平面坐标：交点在平面内正交基u、v上的投影除以UVScale后取小数部分，纹理每隔UVScale重复一次。
+平面没有有限的包围盒，场景开启BVH时会放在层次结构之外单独求交。
+*/
+type Plane struct {
+	Point    Point
+	Normal   Vec3    // 单位法线
+	UVScale  float64 // 纹理平铺尺寸
+	Material MaterialI
+	AABB     *AABB
+	u, v     Vec3    // 平面内的正交基
+	d        float64 // 平面方程中的常数D
+}
+
+func NewPlane(point Point, normal Vec3) *Plane {
+	normal = normal.Normalize()
+	u, v := orthonormalBasis(normal)
+	plane := &Plane{
+		Point:   point,
+		Normal:  normal,
+		UVScale: 1,
+		u:       u,
+		v:       v,
+		d:       normal.Dot(Vec3(point)),
+	}
+	plane.SetBoundingBox(nil)
+	return plane
+}
+
+func (plane *Plane) WithMaterial(mat MaterialI) *Plane {
+	plane.Material = mat
+	return plane
+}
+
+// WithUVScale 设置纹理平铺尺寸，必须为正数
+func (plane *Plane) WithUVScale(scale float64) *Plane {
+	plane.UVScale = scale
+	return plane
+}
+
+func (plane *Plane) GetMaterial() MaterialI {
+	return plane.Material
+}
+
+// SetBoundingBox 传入nil时为无穷大的包围盒
+func (plane *Plane) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		plane.AABB = aabb
+		return
+	}
+	plane.AABB = NewAABB(NewUniverseInterval(), NewUniverseInterval(), NewUniverseInterval())
+}
+
+func (plane *Plane) GetBoundingBox() *AABB {
+	return plane.AABB
+}
+
+func (plane *Plane) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	denom := plane.Normal.Dot(ray.Direction)
+	// 射线与平面平行
+	if math.Abs(denom) < 1e-8 {
+		return false, hitRecord
+	}
+	t := (plane.d - plane.Normal.Dot(Vec3(ray.Origin))) / denom
+	if !rayT.Surrounds(t) {
+		return false, hitRecord
+	}
+	hitRecord.Time = t
+	hitRecord.HitPoint = ray.At(t)
+	local := Vec3(hitRecord.HitPoint).Sub(Vec3(plane.Point))
+	hitRecord.setFaceNormal(ray, plane.Normal)
+	hitRecord.U = fract(local.Dot(plane.u) / plane.UVScale)
+	hitRecord.V = fract(local.Dot(plane.v) / plane.UVScale)
+	hitRecord.Material = plane.Material
+	return true, hitRecord
+}
+
+// fract 取小数部分，负数也落在[0,1)内
+func fract(x float64) float64 {
+	return x - math.Floor(x)
+}
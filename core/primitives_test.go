@@ -0,0 +1,205 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+type primitiveHitTest struct {
+	name   string
+	ray    Ray
+	hit    bool
+	time   float64
+	normal Vec3
+	front  bool
+}
+
+func checkPrimitiveHits(t *testing.T, item HittableItemI, tests []primitiveHitTest) {
+	t.Helper()
+	for _, test := range tests {
+		hit, record := item.Hittable(test.ray, NewInterval(1e-5, math.Inf(1)))
+		if hit != test.hit {
+			t.Errorf("%s: hit = %v", test.name, hit)
+			continue
+		}
+		if !hit {
+			continue
+		}
+		if math.Abs(record.Time-test.time) > 1e-6 || !vecNear(record.Normal, test.normal) || record.FrontFace != test.front {
+			t.Errorf("%s: t=%v normal=%v front=%v", test.name, record.Time, record.Normal, record.FrontFace)
+		}
+		if record.U < 0 || record.U > 1 || record.V < 0 || record.V > 1 {
+			t.Errorf("%s: uv=(%v,%v) out of [0,1]", test.name, record.U, record.V)
+		}
+		// 击中点必须在包围盒内
+		if box := item.GetBoundingBox(); !box.X.Contains(record.HitPoint.X) || !box.Y.Contains(record.HitPoint.Y) || !box.Z.Contains(record.HitPoint.Z) {
+			t.Errorf("%s: hit point %v outside bounding box %+v", test.name, record.HitPoint, box)
+		}
+	}
+}
+
+func vecNear(a, b Vec3) bool {
+	return a.Sub(b).Length() < 1e-6
+}
+
+func TestDisk(t *testing.T) {
+	disk := NewDisk(Point{Y: 1}, Vec3{Y: 2}, 2)
+	if box := disk.GetBoundingBox(); box.X != (Interval{-2, 2}) || box.Y.Size() <= 0 {
+		t.Errorf("bounding box %+v", box)
+	}
+	checkPrimitiveHits(t, disk, []primitiveHitTest{
+		{"above", NewRay(Point{X: 1, Y: 5}, Vec3{Y: -1}), true, 4, Vec3{Y: 1}, true},
+		{"below", NewRay(Point{Z: -1.5, Y: -1}, Vec3{Y: 2}), true, 2, Vec3{Y: -1}, false},
+		{"outside radius", NewRay(Point{X: 2.1, Y: 5}, Vec3{Y: -1}), false, 0, Vec3{}, false},
+		{"parallel", NewRay(Point{Y: 1, X: -5}, Vec3{X: 1}), false, 0, Vec3{}, false},
+	})
+	// 倾斜圆盘的包围盒按投影计算
+	tilted := NewDisk(Point{}, Vec3{X: 1, Y: 1}, 1)
+	if size := tilted.GetBoundingBox().X.Size(); math.Abs(size-math.Sqrt2) > 1e-9 {
+		t.Errorf("tilted disk width %v", size)
+	}
+	_, record := disk.Hittable(NewRay(Point{Y: 5}, Vec3{Y: -1}), NewInterval(1e-5, math.Inf(1)))
+	if record.V != 0 {
+		t.Errorf("center v = %v", record.V)
+	}
+}
+
+func TestCylinder(t *testing.T) {
+	cylinder := NewCylinder(Point{Y: -1}, 1, 2)
+	checkPrimitiveHits(t, cylinder, []primitiveHitTest{
+		{"side", NewRay(Point{Z: 5}, Vec3{Z: -1}), true, 4, Vec3{Z: 1}, true},
+		{"top", NewRay(Point{X: 0.5, Y: 5}, Vec3{Y: -1}), true, 4, Vec3{Y: 1}, true},
+		{"bottom", NewRay(Point{X: 0.5, Y: -5}, Vec3{Y: 1}), true, 4, Vec3{Y: -1}, true},
+		{"inside", NewRay(Point{}, Vec3{X: 1}), true, 1, Vec3{X: -1}, false},
+		{"inside to cap", NewRay(Point{}, Vec3{Y: 1}), true, 1, Vec3{Y: -1}, false},
+		{"above", NewRay(Point{Y: 1.5, Z: 5}, Vec3{Z: -1}), false, 0, Vec3{}, false},
+		{"diagonal through cap", NewRay(Point{Y: 3, Z: 2.5}, Vec3{Y: -1, Z: -1}), true, 2 * math.Sqrt2, Vec3{Y: 1}, true},
+	})
+	cylinder.Capped = false
+	checkPrimitiveHits(t, cylinder, []primitiveHitTest{
+		{"open top", NewRay(Point{X: 0.5, Y: 5}, Vec3{Y: -1}), false, 0, Vec3{}, false},
+		{"open inside", NewRay(Point{X: 0.5, Y: 5}, Vec3{X: 0.5, Y: -4.5}), true, math.Sqrt(20.5), Vec3{X: -1}, false},
+	})
+}
+
+func TestCone(t *testing.T) {
+	cone := NewCone(Point{}, 1, 1)
+	s := math.Sqrt(0.5)
+	checkPrimitiveHits(t, cone, []primitiveHitTest{
+		{"side", NewRay(Point{Y: 0.5, Z: 5}, Vec3{Z: -1}), true, 4.5, Vec3{Y: s, Z: s}, true},
+		{"base", NewRay(Point{X: 0.5, Y: -5}, Vec3{Y: 1}), true, 5, Vec3{Y: -1}, true},
+		{"apex", NewRay(Point{Y: 5}, Vec3{Y: -1}), true, 4, Vec3{Y: 1}, true},
+		{"beside apex", NewRay(Point{Y: 0.9, Z: 5}, Vec3{Z: 1}), false, 0, Vec3{}, false},
+		{"upper nappe", NewRay(Point{Y: 1.5, Z: 5}, Vec3{Z: -1}), false, 0, Vec3{}, false},
+		{"inside", NewRay(Point{Y: 0.25}, Vec3{X: 1}), true, 0.75, Vec3{X: -s, Y: -s}, false},
+	})
+}
+
+func TestTorus(t *testing.T) {
+	torus := NewTorus(Point{Y: 1}, 2, 0.5)
+	if box := torus.GetBoundingBox(); box.X != (Interval{-2.5, 2.5}) || box.Y != (Interval{0.5, 1.5}) {
+		t.Errorf("bounding box %+v", box)
+	}
+	checkPrimitiveHits(t, torus, []primitiveHitTest{
+		{"outer", NewRay(Point{X: 10, Y: 1}, Vec3{X: -2}), true, 7.5, Vec3{X: 1}, true},
+		{"through hole", NewRay(Point{Y: 10}, Vec3{Y: -1}), false, 0, Vec3{}, false},
+		{"top", NewRay(Point{X: 2, Y: 10}, Vec3{Y: -1}), true, 8.5, Vec3{Y: 1}, true},
+		{"inner from hole", NewRay(Point{Y: 1}, Vec3{Z: 1}), true, 1.5, Vec3{Z: -1}, true},
+		{"inside tube", NewRay(Point{X: 2, Y: 1}, Vec3{X: 1}), true, 0.5, Vec3{X: -1}, false},
+		{"grazing miss", NewRay(Point{X: -10, Y: 1.51}, Vec3{X: 1}), false, 0, Vec3{}, false},
+	})
+	// 倾斜射线的交点满足隐式方程
+	ray := NewRay(Point{X: -5, Y: 2.5, Z: 0.3}, Vec3{X: 1, Y: -0.35, Z: 0.1})
+	if hit, record := torus.Hittable(ray, NewInterval(1e-5, math.Inf(1))); hit {
+		p := Vec3(record.HitPoint).Sub(Vec3(torus.Center))
+		f := math.Pow(p.LengthSquared()+4-0.25, 2) - 16*(p.X*p.X+p.Z*p.Z)
+		if math.Abs(f) > 1e-6 {
+			t.Errorf("hit point %v does not satisfy the torus equation: %v", record.HitPoint, f)
+		}
+	} else {
+		t.Error("tilted ray missed the torus")
+	}
+}
+
+func TestSolveQuartic(t *testing.T) {
+	// (x-1)(x-2)(x+3)(x-0.5) = x⁴ - 0.5x³ - 7x² + 9.5x - 3
+	roots := solveQuartic([5]float64{-3, 9.5, -7, -0.5, 1})
+	want := []float64{-3, 0.5, 1, 2}
+	if len(roots) != len(want) {
+		t.Fatalf("roots = %v, want %v", roots, want)
+	}
+	for i := range want {
+		if math.Abs(roots[i]-want[i]) > 1e-9 {
+			t.Errorf("roots = %v, want %v", roots, want)
+			break
+		}
+	}
+	// x⁴ + 1 没有实根
+	if roots := solveQuartic([5]float64{1, 0, 0, 0, 1}); len(roots) != 0 {
+		t.Errorf("roots of x^4+1 = %v", roots)
+	}
+}
+
+func TestPlane(t *testing.T) {
+	plane := NewPlane(Point{Y: -1}, Vec3{Y: 3})
+	if plane.GetBoundingBox().IsBounded() {
+		t.Error("plane bounding box should be unbounded")
+	}
+	tests := []primitiveHitTest{
+		{"above", NewRay(Point{X: 100, Y: 1, Z: -250}, Vec3{Y: -1}), true, 2, Vec3{Y: 1}, true},
+		{"below", NewRay(Point{Y: -4}, Vec3{Y: 1}), true, 3, Vec3{Y: -1}, false},
+		{"away", NewRay(Point{Y: 1}, Vec3{Y: 1}), false, 0, Vec3{}, false},
+		{"parallel", NewRay(Point{Y: 1}, Vec3{X: 1}), false, 0, Vec3{}, false},
+	}
+	for _, test := range tests {
+		hit, record := plane.Hittable(test.ray, NewInterval(1e-5, math.Inf(1)))
+		if hit != test.hit {
+			t.Errorf("%s: hit = %v", test.name, hit)
+			continue
+		}
+		if hit && (math.Abs(record.Time-test.time) > 1e-9 || !vecNear(record.Normal, test.normal) || record.FrontFace != test.front) {
+			t.Errorf("%s: t=%v normal=%v front=%v", test.name, record.Time, record.Normal, record.FrontFace)
+		}
+	}
+	// 平面UV随坐标平铺，相距UVScale的两点UV相同
+	plane.WithUVScale(2)
+	_, a := plane.Hittable(NewRay(Point{X: 0.5, Y: 1, Z: -0.3}, Vec3{Y: -1}), NewInterval(1e-5, math.Inf(1)))
+	_, b := plane.Hittable(NewRay(Point{X: 0.5 + 2*plane.u.X, Y: 1, Z: -0.3 + 2*plane.u.Z}, Vec3{Y: -1}), NewInterval(1e-5, math.Inf(1)))
+	if math.Abs(a.U-b.U) > 1e-9 || math.Abs(a.V-b.V) > 1e-9 || a.U < 0 || a.U >= 1 || a.V < 0 || a.V >= 1 {
+		t.Errorf("uv (%v,%v) and (%v,%v)", a.U, a.V, b.U, b.V)
+	}
+}
+
+func TestScenesUnbounded(t *testing.T) {
+	var world Scenes
+	world.HittableAABB = NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval())
+	ground := NewPlane(Point{}, Vec3{Y: 1})
+	world.Add(ground, NewSphere(Point{Y: 1}, 1), NewSphere(Point{X: 3, Y: 1}, 1), NewSphere(Point{X: -3, Y: 1}, 1))
+	if !world.GetBoundingBox().IsBounded() {
+		t.Errorf("scene bounding box %+v includes the plane", world.GetBoundingBox())
+	}
+	world.EnabledBVH = true
+	world.BuildBVH()
+	if len(world.Unbounded) != 1 || world.Unbounded[0] != ground || !world.BVH.GetBoundingBox().IsBounded() {
+		t.Fatalf("unbounded = %v, bvh box = %+v", world.Unbounded, world.BVH.GetBoundingBox())
+	}
+	for _, ray := range []Ray{
+		NewRay(Point{Y: 5, Z: 10}, Vec3{Z: -1, Y: -0.5}),
+		NewRay(Point{X: 3, Y: 10}, Vec3{Y: -1}),
+		NewRay(Point{X: 10, Y: 1}, Vec3{X: -1}),
+		NewRay(Point{X: 1.5, Y: 10}, Vec3{Y: -1}),
+	} {
+		bvhHit, bvhRecord := world.Hit(ray, NewInterval(1e-5, math.Inf(1)))
+		linearHit, linearRecord := world.HitAnything(ray, NewInterval(1e-5, math.Inf(1)))
+		if bvhHit != linearHit || bvhRecord.Time != linearRecord.Time || bvhRecord.Object != linearRecord.Object {
+			t.Errorf("ray %v: bvh (%v, %v, %T) linear (%v, %v, %T)", ray, bvhHit, bvhRecord.Time, bvhRecord.Object, linearHit, linearRecord.Time, linearRecord.Object)
+		}
+	}
+	// 只有无界物体时不构建BVH
+	planes := Scenes{HittableAABB: NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval()), EnabledBVH: true}
+	planes.Add(ground)
+	planes.BuildBVH()
+	if hit, record := planes.Hit(NewRay(Point{Y: 1}, Vec3{Y: -1}), NewInterval(1e-5, math.Inf(1))); planes.BVH != nil || !hit || record.Object != ground {
+		t.Errorf("plane-only scene: bvh %v hit %v", planes.BVH, hit)
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrthographicRaysAreParallel(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 2, 90, 40, 1, 4, true, 0, 1)
+	camera.SetOrthographic(4)
+	a := camera.GetRayWithOffset(0, 0, Vec3{})
+	b := camera.GetRayWithOffset(39, 19, Vec3{})
+	if a.Direction != b.Direction || a.Direction.Sub(Vec3{0, 0, -1}).Length() > 1e-9 {
+		t.Fatalf("directions differ: %v %v", a.Direction, b.Direction)
+	}
+	// 视口宽8高4，像素中心内缩半个像素(0.1)
+	if math.Abs(a.Origin.X+3.9) > 1e-9 || math.Abs(a.Origin.Y-1.9) > 1e-9 || a.Origin.Z != 0 {
+		t.Errorf("top-left origin = %v", a.Origin)
+	}
+	if math.Abs(b.Origin.X-3.9) > 1e-9 || math.Abs(b.Origin.Y+1.9) > 1e-9 {
+		t.Errorf("bottom-right origin = %v", b.Origin)
+	}
+
+	camera.SetPerspective()
+	p := camera.GetRayWithOffset(20, 10, Vec3{X: -0.5, Y: -0.5})
+	if p.Origin != camera.CameraCenter || math.Abs(p.Direction.Z+1) > 1e-9 {
+		t.Errorf("perspective center ray = %+v", p)
+	}
+}
+
+func TestPanoramicCameraModels(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 2, 90, 200, 1, 4, true, 0, 1)
+	camera.SetCameraModel(EquirectangularModel{})
+	forward := camera.GetRayWithOffset(100, 50, Vec3{X: -0.5, Y: -0.5})
+	if forward.Direction.Sub(Vec3{0, 0, -1}).Length() > 1e-9 {
+		t.Errorf("equirectangular center direction = %v", forward.Direction)
+	}
+	up := camera.GetRayWithOffset(100, 0, Vec3{X: -0.5, Y: -0.5})
+	if up.Direction.Sub(Vec3{0, 1, 0}).Length() > 1e-9 {
+		t.Errorf("equirectangular top direction = %v", up.Direction)
+	}
+	back := camera.GetRayWithOffset(0, 50, Vec3{X: -0.5, Y: -0.5})
+	if back.Direction.Sub(Vec3{0, 0, 1}).Length() > 1e-9 {
+		t.Errorf("equirectangular left edge direction = %v", back.Direction)
+	}
+
+	for _, mapping := range []FisheyeMapping{EquidistantMapping, EquisolidMapping} {
+		fisheye, err := NewFisheyeModel(180, mapping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		camera.SetCameraModel(fisheye)
+		if r := camera.GetRayWithOffset(0, 0, Vec3{}); r != nil {
+			t.Errorf("%s: corner outside the image circle produced a ray", mapping)
+		}
+		center := camera.GetRayWithOffset(100, 50, Vec3{X: -0.5, Y: -0.5})
+		if center.Direction.Sub(Vec3{0, 0, -1}).Length() > 1e-9 {
+			t.Errorf("%s: center direction = %v", mapping, center.Direction)
+		}
+		// 成像圆右边缘对应90°
+		edge := camera.GetRayWithOffset(150, 50, Vec3{X: -0.5, Y: -0.5})
+		if edge.Direction.Sub(Vec3{1, 0, 0}).Length() > 1e-9 {
+			t.Errorf("%s: edge direction = %v", mapping, edge.Direction)
+		}
+	}
+	if _, err := NewFisheyeModel(180, "stereographic"); err == nil {
+		t.Error("expected error for unknown mapping")
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import "math"
+
+/*
+四边形：由一个角点Q和两条边向量U、V定义，四个顶点为Q、Q+U、Q+V、Q+U+V。
+先求射线与四边形所在平面 n·P = D 的交点，再把交点表示为 P = Q + αU + βV，
+α、β都在[0,1]内时交点落在四边形中，(α,β)即为该点的UV坐标。
+*/
+type Quad struct {
+	Q        Point
+	U, V     Vec3
+	Material MaterialI
+	AABB     *AABB
+	normal   Vec3    // 单位法线，U×V方向
+	d        float64 // 平面方程中的常数D
+	w        Vec3    // n/(n·n)，用于求α、β
+}
+
+func NewQuad(q Point, u, v Vec3) *Quad {
+	n := u.Cross(v)
+	normal := n.Normalize()
+	quad := &Quad{
+		Q:      q,
+		U:      u,
+		V:      v,
+		normal: normal,
+		d:      normal.Dot(Vec3(q)),
+		w:      n.Div(n.Dot(n)),
+	}
+	quad.SetBoundingBox(nil)
+	return quad
+}
+
+func (q *Quad) WithMaterial(mat MaterialI) *Quad {
+	q.Material = mat
+	return q
+}
+
+func (q *Quad) GetMaterial() MaterialI {
+	return q.Material
+}
+
+// SetBoundingBox 传入nil时按四个顶点计算，四边形与坐标轴平行时包围盒在该轴上没有厚度，需要稍微扩展
+func (q *Quad) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		q.AABB = aabb
+		return
+	}
+	diagonal1 := NewAABBFromPoints(q.Q, Point(Vec3(q.Q).Add(q.U).Add(q.V)))
+	diagonal2 := NewAABBFromPoints(Point(Vec3(q.Q).Add(q.U)), Point(Vec3(q.Q).Add(q.V)))
+	q.AABB = padAABB(NewAABBFromAABB(diagonal1, diagonal2), 1e-4)
+}
+
+func (q *Quad) GetBoundingBox() *AABB {
+	return q.AABB
+}
+
+func (q *Quad) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	denom := q.normal.Dot(ray.Direction)
+	// 射线与平面平行
+	if math.Abs(denom) < 1e-8 {
+		return false, hitRecord
+	}
+	t := (q.d - q.normal.Dot(Vec3(ray.Origin))) / denom
+	if !rayT.Surrounds(t) {
+		return false, hitRecord
+	}
+	intersection := ray.At(t)
+	planar := Vec3(intersection).Sub(Vec3(q.Q))
+	alpha := q.w.Dot(planar.Cross(q.V))
+	beta := q.w.Dot(q.U.Cross(planar))
+	unit := Interval{0, 1}
+	if !unit.Contains(alpha) || !unit.Contains(beta) {
+		return false, hitRecord
+	}
+	hitRecord.Time = t
+	hitRecord.HitPoint = intersection
+	hitRecord.U, hitRecord.V = alpha, beta
+	if denom < 0 {
+		hitRecord.FrontFace = true
+		hitRecord.Normal = q.normal
+	} else {
+		hitRecord.FrontFace = false
+		hitRecord.Normal = q.normal.MultiplicationNum(-1.0)
+	}
+	hitRecord.Material = q.Material
+	return true, hitRecord
+}
+
+// padAABB 把厚度小于delta的轴扩展到delta
+func padAABB(aabb *AABB, delta float64) *AABB {
+	pad := func(i Interval) Interval {
+		if i.Size() < delta {
+			return *i.Expand(delta)
+		}
+		return i
+	}
+	return NewAABB(pad(aabb.X), pad(aabb.Y), pad(aabb.Z))
+}
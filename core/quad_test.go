@@ -0,0 +1,30 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuadHit(t *testing.T) {
+	quad := NewQuad(Point{X: -1, Y: -1, Z: -2}, Vec3{X: 2}, Vec3{Y: 2})
+	hit, record := quad.Hittable(NewRay(Point{X: 0.5, Y: -0.5}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1)))
+	if !hit {
+		t.Fatal("expected a hit")
+	}
+	if math.Abs(record.Time-2) > 1e-9 || math.Abs(record.U-0.75) > 1e-9 || math.Abs(record.V-0.25) > 1e-9 {
+		t.Errorf("t=%v uv=(%v,%v)", record.Time, record.U, record.V)
+	}
+	if !record.FrontFace || record.Normal != (Vec3{Z: 1}) {
+		t.Errorf("front=%v normal=%v", record.FrontFace, record.Normal)
+	}
+	if hit, _ := quad.Hittable(NewRay(Point{X: 1.5}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1))); hit {
+		t.Error("ray outside the quad hit it")
+	}
+	if hit, _ := quad.Hittable(NewRay(Point{}, Vec3{X: 1}), NewInterval(1e-5, math.Inf(1))); hit {
+		t.Error("parallel ray hit the quad")
+	}
+	// 与坐标轴平行的四边形包围盒需要有厚度，否则BVH中无法被击中
+	if quad.GetBoundingBox().Z.Size() <= 0 {
+		t.Errorf("flat bounding box %v", quad.GetBoundingBox())
+	}
+}
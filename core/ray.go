@@ -1,17 +1,21 @@
 package core
 
+import "math/rand"
+
 type Ray struct {
 	Origin    Point // 起点
 	Direction Vec3  // 方向,单位向量
 	TM        float64
+	counters  *rayCounters // 渲染统计计数器，随散射射线传递，nil时不统计
+	rng       *rand.Rand   // 渲染协程的随机数生成器，随散射射线传递，nil时使用全局随机源
 }
 
 func NewRay(origin Point, direction Vec3) Ray {
-	return Ray{origin, direction.Normalize(), 0.0}
+	return Ray{Origin: origin, Direction: direction.Normalize()}
 }
 
 func NewRayWithTime(origin Point, direction Vec3, tm float64) Ray {
-	return Ray{origin, direction.Normalize(), tm}
+	return Ray{Origin: origin, Direction: direction.Normalize(), TM: tm}
 }
 
 func (r Ray) Time() float64 {
@@ -0,0 +1,1465 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+JSON场景文件（版本1）：
+{
+  "version": 1,
+  "camera":    { "lookFrom": [13,2,3], "lookAt": [0,0,0], "aspectRatio": 1.78, "vfov": 20, "defocusAngle": 0.6, "focusDist": 10,
+                 "projection": {"type": "perspective"}, "aperture": {"type": "polygon", "blades": 6}, "shutter": {"open": 0, "close": 1} },
+  "render":    { "width": 400, "height": 225, "samplesPerPixel": 100, "maxDepth": 50, "bvh": true, "filter": {"type": "gaussian"}, "threads": 8, "output": "o" },
+  "textures":  { "checker": {"type": "checker", "invScale": 0.32, "even": [0.2,0.3,0.1], "odd": [0.9,0.9,0.9]} },
+  "materials": { "ground": {"type": "lambertian", "texture": "checker"}, "glass": {"type": "dielectric", "refractionIndex": 1.5} },
+  "objects":   [ {"type": "plane", "point": [0,0,0], "normal": [0,1,0], "material": "ground"},
+                 {"type": "sphere", "center": [0,1,0], "radius": 1, "material": "glass", "moveTo": [0,1.5,0], "moveTime": [0,1]},
+                 {"type": "transform", "translate": [0,1,0], "object": {...}},
+                 {"type": "animated", "interpolation": "bezier", "keyframes": [...], "object": {...}},
+                 {"type": "quad", "corner": [0,0,0], "u": [1,0,0], "v": [0,1,0], "material": "light"},
+                 {"type": "constant_medium", "density": 0.01, "material": "smoke", "object": {...}},
+                 {"type": "bvh", "objects": [...]},
+                 {"type": "box", "min": [0,0,0], "max": [1,1,1], "material": "white"},
+                 {"type": "disk", "center": [0,1,0], "normal": [0,1,0], "radius": 1, "material": "gold"},
+                 {"type": "cylinder", "center": [0,0,0], "radius": 1, "height": 2, "capped": false, "material": "red"},
+                 {"type": "cone", "center": [0,0,0], "radius": 1, "height": 2, "material": "blue"},
+                 {"type": "torus", "center": [0,0,0], "majorRadius": 1, "minorRadius": 0.3, "material": "glass"},
+                 {"type": "csg", "operation": "difference", "a": {...}, "b": {...}, "material": "gold"},
+                 {"type": "heightfield", "path": "height.png", "origin": [-10,0,-10], "size": [20,4,20], "material": "rock"} ]
+}
+向量统一写作[x,y,z]，材质和纹理在对象中按名称引用。camera.background 为纯色背景，缺省时为天空渐变。render.height 缺省时由宽度和宽高比计算。
+图片光圈写作 {"type": "image", "path": "bokeh.png"}，也可以用 width、height、weights 直接给出灰度值；文件路径相对于当前工作目录。
+纹理类型有 solid、checker、noise（scale）、image（path）和程序生成的 globe；noise 和 globe 的随机格点在加载时由全局随机数生成。
+材质类型有 lambertian、metal、dielectric、diffuse_light（emit 颜色或 texture）和 isotropic（albedo 或 texture），
+constant_medium 的 material 必须是 isotropic 材质。bvh 把一组有界物体组织成层次结构，保存时按节点写成嵌套的 bvh。
+csg 的 operation 为 union、intersection 或 difference，csg 带有材质时其中的物体可以省略材质。
+高度场由灰度图 path 给出，或者用 width、depth 和按行存储的 heights 直接给出高度值。
+球体的 moveTime 为匀速运动的起止时刻，缺省为[0,1]，这段时间之外球体停在起点或终点。
+所有未知字段、类型错误和取值错误都会带上出错位置报告，如 objects[3].radius: must be positive。
+*/
+
+// SceneFileVersion 当前场景文件格式版本
+const SceneFileVersion = 1
+
+// RenderSettings 场景文件中与相机无关的渲染设置
+type RenderSettings struct {
+	Threads int    // 渲染协程数，<=1为单线程
+	Output  string // 输出文件名（可带扩展名）
+}
+
+// SceneError 场景文件错误，Path指出出错位置
+type SceneError struct {
+	Path string
+	Err  error
+}
+
+func (e *SceneError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *SceneError) Unwrap() error {
+	return e.Err
+}
+
+func sceneErrorf(path, format string, args ...any) error {
+	return &SceneError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+type vecJSON []float64
+
+type sceneJSON struct {
+	Version   int                        `json:"version"`
+	Camera    json.RawMessage            `json:"camera"`
+	Render    json.RawMessage            `json:"render,omitempty"`
+	Textures  map[string]json.RawMessage `json:"textures,omitempty"`
+	Materials map[string]json.RawMessage `json:"materials,omitempty"`
+	Objects   []json.RawMessage          `json:"objects"`
+}
+
+type cameraJSON struct {
+	LookFrom          vecJSON         `json:"lookFrom"`
+	LookAt            vecJSON         `json:"lookAt"`
+	AspectRatio       float64         `json:"aspectRatio,omitempty"`
+	VFov              float64         `json:"vfov,omitempty"`
+	DefocusAngle      float64         `json:"defocusAngle,omitempty"`
+	FocusDist         float64         `json:"focusDist,omitempty"`
+	Projection        *projectionJSON `json:"projection,omitempty"`
+	Aperture          *apertureJSON   `json:"aperture,omitempty"`
+	OpticalVignetting float64         `json:"opticalVignetting,omitempty"`
+	Shutter           *shutterJSON    `json:"shutter,omitempty"`
+	Background        vecJSON         `json:"background,omitempty"`
+}
+
+type projectionJSON struct {
+	Type       string  `json:"type"`
+	ViewHeight float64 `json:"viewHeight,omitempty"`
+	FOV        float64 `json:"fov,omitempty"`
+	Mapping    string  `json:"mapping,omitempty"`
+}
+
+type apertureJSON struct {
+	Type     string    `json:"type"`
+	Blades   int       `json:"blades,omitempty"`
+	Rotation float64   `json:"rotation,omitempty"`
+	Width    int       `json:"width,omitempty"`
+	Height   int       `json:"height,omitempty"`
+	Weights  []float64 `json:"weights,omitempty"`
+	Path     string    `json:"path,omitempty"`
+}
+
+type shutterJSON struct {
+	Open       float64 `json:"open"`
+	Close      float64 `json:"close"`
+	Shape      string  `json:"shape,omitempty"`
+	MotionBlur *bool   `json:"motionBlur,omitempty"`
+}
+
+type renderJSON struct {
+	Width           int          `json:"width,omitempty"`
+	Height          int          `json:"height,omitempty"`
+	SamplesPerPixel int          `json:"samplesPerPixel,omitempty"`
+	MaxDepth        int          `json:"maxDepth,omitempty"`
+	Antialiased     *bool        `json:"antialiased,omitempty"`
+	BVH             bool         `json:"bvh,omitempty"`
+	AOV             bool         `json:"aov,omitempty"`
+	Filter          *filterJSON  `json:"filter,omitempty"`
+	Denoise         *denoiseJSON `json:"denoise,omitempty"`
+	Threads         int          `json:"threads,omitempty"`
+	Output          string       `json:"output,omitempty"`
+}
+
+type filterJSON struct {
+	Type   string  `json:"type"`
+	Radius float64 `json:"radius,omitempty"`
+}
+
+type denoiseJSON struct {
+	Strength    float64 `json:"strength"`
+	Iterations  int     `json:"iterations,omitempty"`
+	ColorSigma  float64 `json:"colorSigma,omitempty"`
+	NormalSigma float64 `json:"normalSigma,omitempty"`
+	AlbedoSigma float64 `json:"albedoSigma,omitempty"`
+}
+
+type textureJSON struct {
+	Type     string  `json:"type"`
+	Color    vecJSON `json:"color,omitempty"`
+	InvScale float64 `json:"invScale,omitempty"`
+	Even     vecJSON `json:"even,omitempty"`
+	Odd      vecJSON `json:"odd,omitempty"`
+	Scale    float64 `json:"scale,omitempty"`
+	Path     string  `json:"path,omitempty"`
+}
+
+type materialJSON struct {
+	Type            string  `json:"type"`
+	Albedo          vecJSON `json:"albedo,omitempty"`
+	Emit            vecJSON `json:"emit,omitempty"`
+	Texture         string  `json:"texture,omitempty"`
+	Fuzz            float64 `json:"fuzz,omitempty"`
+	RefractionIndex float64 `json:"refractionIndex,omitempty"`
+}
+
+// objectJSON 所有物体类型共用的字段，各类型只使用其中一部分，多余的字段视为错误
+type objectJSON struct {
+	Type     string    `json:"type"`
+	Material string    `json:"material,omitempty"`
+	Center   vecJSON   `json:"center,omitempty"`
+	Radius   float64   `json:"radius,omitempty"`
+	MoveTo   vecJSON   `json:"moveTo,omitempty"`
+	MoveTime []float64 `json:"moveTime,omitempty"`
+	// plane
+	Point   vecJSON `json:"point,omitempty"`
+	Normal  vecJSON `json:"normal,omitempty"`
+	UVScale float64 `json:"uvScale,omitempty"`
+	// transform
+	Translate vecJSON `json:"translate,omitempty"`
+	Rotate    vecJSON `json:"rotate,omitempty"`
+	Scale     vecJSON `json:"scale,omitempty"`
+	// animated
+	Interpolation string          `json:"interpolation,omitempty"`
+	Pivot         vecJSON         `json:"pivot,omitempty"`
+	Keyframes     []keyframeJSON  `json:"keyframes,omitempty"`
+	Object        json.RawMessage `json:"object,omitempty"`
+	// quad
+	Corner vecJSON `json:"corner,omitempty"`
+	U      vecJSON `json:"u,omitempty"`
+	V      vecJSON `json:"v,omitempty"`
+	// constant_medium
+	Density float64 `json:"density,omitempty"`
+	// bvh
+	Objects []json.RawMessage `json:"objects,omitempty"`
+	// box
+	Min vecJSON `json:"min,omitempty"`
+	Max vecJSON `json:"max,omitempty"`
+	// cylinder, cone, torus
+	Height      float64 `json:"height,omitempty"`
+	Capped      *bool   `json:"capped,omitempty"`
+	MajorRadius float64 `json:"majorRadius,omitempty"`
+	MinorRadius float64 `json:"minorRadius,omitempty"`
+	// csg
+	Operation string          `json:"operation,omitempty"`
+	A         json.RawMessage `json:"a,omitempty"`
+	B         json.RawMessage `json:"b,omitempty"`
+	// heightfield
+	Path    string    `json:"path,omitempty"`
+	Origin  vecJSON   `json:"origin,omitempty"`
+	Size    vecJSON   `json:"size,omitempty"`
+	Width   int       `json:"width,omitempty"`
+	Depth   int       `json:"depth,omitempty"`
+	Heights []float64 `json:"heights,omitempty"`
+}
+
+type keyframeJSON struct {
+	Time     float64 `json:"time"`
+	Position vecJSON `json:"position"`
+	Rotation vecJSON `json:"rotation,omitempty"`
+	Scale    vecJSON `json:"scale,omitempty"`
+}
+
+// objectFields 每种物体允许出现的字段
+var objectFields = map[string][]string{
+	"sphere":          {"type", "material", "center", "radius", "moveTo", "moveTime"},
+	"plane":           {"type", "material", "point", "normal", "uvScale"},
+	"transform":       {"type", "translate", "rotate", "scale", "object"},
+	"animated":        {"type", "interpolation", "pivot", "keyframes", "object"},
+	"quad":            {"type", "material", "corner", "u", "v"},
+	"bvh":             {"type", "objects"},
+	"constant_medium": {"type", "material", "density", "object"},
+	"box":             {"type", "material", "min", "max"},
+	"disk":            {"type", "material", "center", "normal", "radius"},
+	"cylinder":        {"type", "material", "center", "radius", "height", "capped"},
+	"cone":            {"type", "material", "center", "radius", "height", "capped"},
+	"torus":           {"type", "material", "center", "majorRadius", "minorRadius"},
+	"csg":             {"type", "material", "operation", "a", "b"},
+	"heightfield":     {"type", "material", "path", "origin", "size", "width", "depth", "heights"},
+}
+
+// decodeStrict 严格解码，未知字段报错，错误信息带上路径和出错的行列
+func decodeStrict(data []byte, v any, path string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			line, col := lineColumn(data, syntaxErr.Offset)
+			return sceneErrorf(path, "invalid JSON at line %d, column %d: %v", line, col, err)
+		case errors.As(err, &typeErr):
+			field := typeErr.Field
+			if path != "" && field != "" {
+				field = path + "." + field
+			} else if field == "" {
+				field = path
+			}
+			return sceneErrorf(field, "expected %s, got JSON %s", typeErr.Type, typeErr.Value)
+		case errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+			return sceneErrorf(path, "invalid JSON: unexpected end of input")
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return sceneErrorf(path, "unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		default:
+			return &SceneError{Path: path, Err: err}
+		}
+	}
+	return nil
+}
+
+func lineColumn(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for i := int64(0); i < offset-1 && i < int64(len(data)); i++ { // Offset指向出错字符之后
+		if data[i] == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+func (v vecJSON) vec3(path string) (Vec3, error) {
+	if len(v) != 3 {
+		return Vec3{}, sceneErrorf(path, "expected [x, y, z], got %d numbers", len(v))
+	}
+	return Vec3{v[0], v[1], v[2]}, nil
+}
+
+// optionalVec3 缺省时返回def
+func (v vecJSON) optionalVec3(path string, def Vec3) (Vec3, error) {
+	if v == nil {
+		return def, nil
+	}
+	return v.vec3(path)
+}
+
+func toVecJSON(v Vec3) vecJSON {
+	return vecJSON{v.X, v.Y, v.Z}
+}
+
+// sceneLoader 加载过程中的命名纹理和材质
+type sceneLoader struct {
+	textures  map[string]TextureI
+	materials map[string]MaterialI
+	inCSG     bool // 正在加载带材质的CSG中的物体，这些物体可以省略材质
+}
+
+// LoadSceneFile 从文件加载场景
+func LoadSceneFile(path string) (*Camera, RenderSettings, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, RenderSettings{}, err
+	}
+	defer f.Close()
+	camera, settings, err := LoadScene(f)
+	if err != nil {
+		return nil, RenderSettings{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return camera, settings, nil
+}
+
+// LoadScene 读取JSON场景，构建带有场景的相机
+func LoadScene(r io.Reader) (*Camera, RenderSettings, error) {
+	var settings RenderSettings
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, settings, err
+	}
+	var file sceneJSON
+	if err := decodeStrict(data, &file, ""); err != nil {
+		return nil, settings, err
+	}
+	if file.Version == 0 {
+		return nil, settings, sceneErrorf("version", "missing, expected %d", SceneFileVersion)
+	}
+	if file.Version != SceneFileVersion {
+		return nil, settings, sceneErrorf("version", "unsupported version %d, expected %d", file.Version, SceneFileVersion)
+	}
+	if file.Camera == nil {
+		return nil, settings, sceneErrorf("camera", "missing")
+	}
+	var cameraSpec cameraJSON
+	if err := decodeStrict(file.Camera, &cameraSpec, "camera"); err != nil {
+		return nil, settings, err
+	}
+	renderSpec := renderJSON{}
+	if file.Render != nil {
+		if err := decodeStrict(file.Render, &renderSpec, "render"); err != nil {
+			return nil, settings, err
+		}
+	}
+	camera, err := buildCamera(cameraSpec, renderSpec)
+	if err != nil {
+		return nil, settings, err
+	}
+	settings = RenderSettings{Threads: renderSpec.Threads, Output: renderSpec.Output}
+
+	loader := &sceneLoader{textures: map[string]TextureI{}, materials: map[string]MaterialI{}}
+	for _, name := range sortedKeys(file.Textures) {
+		texture, err := loader.texture(file.Textures[name], "textures."+name)
+		if err != nil {
+			return nil, settings, err
+		}
+		loader.textures[name] = texture
+	}
+	for _, name := range sortedKeys(file.Materials) {
+		material, err := loader.material(file.Materials[name], "materials."+name)
+		if err != nil {
+			return nil, settings, err
+		}
+		loader.materials[name] = material
+	}
+	if len(file.Objects) == 0 {
+		return nil, settings, sceneErrorf("objects", "scene has no objects")
+	}
+	for i, raw := range file.Objects {
+		object, err := loader.object(raw, fmt.Sprintf("objects[%d]", i))
+		if err != nil {
+			return nil, settings, err
+		}
+		camera.Add(object)
+	}
+	camera.EnabledBVH(renderSpec.BVH)
+	return camera, settings, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func buildCamera(spec cameraJSON, render renderJSON) (*Camera, error) {
+	lookFrom, err := spec.LookFrom.vec3("camera.lookFrom")
+	if err != nil {
+		return nil, err
+	}
+	lookAt, err := spec.LookAt.vec3("camera.lookAt")
+	if err != nil {
+		return nil, err
+	}
+	if lookFrom == lookAt {
+		return nil, sceneErrorf("camera.lookAt", "must differ from lookFrom")
+	}
+	aspectRatio := defaultFloat(spec.AspectRatio, 16.0/9.0)
+	vFov := defaultFloat(spec.VFov, 90)
+	focusDist := defaultFloat(spec.FocusDist, 10)
+	width := defaultInt(render.Width, 400)
+	spp := defaultInt(render.SamplesPerPixel, 10)
+	maxDepth := defaultInt(render.MaxDepth, 10)
+	switch {
+	case aspectRatio <= 0:
+		return nil, sceneErrorf("camera.aspectRatio", "must be positive, got %v", aspectRatio)
+	case vFov <= 0 || vFov >= 180:
+		return nil, sceneErrorf("camera.vfov", "must be in (0, 180), got %v", vFov)
+	case focusDist <= 0:
+		return nil, sceneErrorf("camera.focusDist", "must be positive, got %v", focusDist)
+	case spec.DefocusAngle < 0:
+		return nil, sceneErrorf("camera.defocusAngle", "must not be negative, got %v", spec.DefocusAngle)
+	case width <= 0:
+		return nil, sceneErrorf("render.width", "must be positive, got %d", width)
+	case render.Height < 0:
+		return nil, sceneErrorf("render.height", "must be positive, got %d", render.Height)
+	case int(float64(width)/aspectRatio) <= 0:
+		return nil, sceneErrorf("render.width", "image of width %d with aspect ratio %v has no rows", width, aspectRatio)
+	case spp <= 0:
+		return nil, sceneErrorf("render.samplesPerPixel", "must be positive, got %d", spp)
+	case maxDepth <= 0:
+		return nil, sceneErrorf("render.maxDepth", "must be positive, got %d", maxDepth)
+	}
+	antialiased := render.Antialiased == nil || *render.Antialiased
+	camera := NewCamera(Point(lookAt), Point(lookFrom), aspectRatio, vFov, width, spp, maxDepth, antialiased, spec.DefocusAngle, focusDist)
+	if render.Height > 0 {
+		// 显式给出的高度优先，避免由宽高比反算时截断丢失一行
+		camera.SetImageSize(width, render.Height)
+	}
+
+	if spec.Projection != nil {
+		model, err := buildProjection(*spec.Projection)
+		if err != nil {
+			return nil, err
+		}
+		camera.SetCameraModel(model)
+	}
+	if spec.Aperture != nil {
+		aperture, err := buildAperture(*spec.Aperture)
+		if err != nil {
+			return nil, err
+		}
+		camera.SetAperture(aperture, spec.OpticalVignetting)
+	} else {
+		camera.OpticalVignetting = spec.OpticalVignetting
+	}
+	if spec.Shutter != nil {
+		shape := ShutterShape(defaultString(spec.Shutter.Shape, string(BoxShutter)))
+		if err := camera.SetShutter(spec.Shutter.Open, spec.Shutter.Close, shape); err != nil {
+			return nil, &SceneError{Path: "camera.shutter", Err: err}
+		}
+		camera.EnabledMotionBlur(spec.Shutter.MotionBlur == nil || *spec.Shutter.MotionBlur)
+	}
+	if spec.Background != nil {
+		background, err := spec.Background.vec3("camera.background")
+		if err != nil {
+			return nil, err
+		}
+		camera.SetBackground(Color(background))
+	}
+	if render.Filter != nil {
+		filter, err := NewFilter(FilterType(render.Filter.Type), render.Filter.Radius)
+		if err != nil {
+			return nil, &SceneError{Path: "render.filter", Err: err}
+		}
+		camera.SetFilter(filter)
+	}
+	if render.Denoise != nil {
+		if render.Denoise.Strength < 0 {
+			return nil, sceneErrorf("render.denoise.strength", "must not be negative, got %v", render.Denoise.Strength)
+		}
+		denoiser := NewDenoiser(render.Denoise.Strength)
+		denoiser.Iterations = defaultInt(render.Denoise.Iterations, denoiser.Iterations)
+		denoiser.ColorSigma = defaultFloat(render.Denoise.ColorSigma, denoiser.ColorSigma)
+		denoiser.NormalSigma = defaultFloat(render.Denoise.NormalSigma, denoiser.NormalSigma)
+		denoiser.AlbedoSigma = defaultFloat(render.Denoise.AlbedoSigma, denoiser.AlbedoSigma)
+		camera.SetDenoiser(denoiser)
+	}
+	camera.EnabledAOV(render.AOV)
+	return camera, nil
+}
+
+func buildProjection(spec projectionJSON) (CameraModelI, error) {
+	switch spec.Type {
+	case "perspective":
+		return ThinLensModel{}, nil
+	case "orthographic":
+		if spec.ViewHeight <= 0 {
+			return nil, sceneErrorf("camera.projection.viewHeight", "must be positive, got %v", spec.ViewHeight)
+		}
+		return OrthographicModel{ViewHeight: spec.ViewHeight}, nil
+	case "equirectangular":
+		return EquirectangularModel{}, nil
+	case "fisheye":
+		model, err := NewFisheyeModel(defaultFloat(spec.FOV, 180), FisheyeMapping(defaultString(spec.Mapping, string(EquidistantMapping))))
+		if err != nil {
+			return nil, &SceneError{Path: "camera.projection", Err: err}
+		}
+		return model, nil
+	default:
+		return nil, sceneErrorf("camera.projection.type", "unknown projection %q (perspective, orthographic, equirectangular, fisheye)", spec.Type)
+	}
+}
+
+func buildAperture(spec apertureJSON) (ApertureI, error) {
+	switch spec.Type {
+	case "circle":
+		return CircularAperture{}, nil
+	case "polygon":
+		aperture, err := NewPolygonAperture(spec.Blades, spec.Rotation)
+		if err != nil {
+			return nil, &SceneError{Path: "camera.aperture.blades", Err: err}
+		}
+		return aperture, nil
+	case "image":
+		if spec.Path != "" {
+			if spec.Width != 0 || spec.Height != 0 || spec.Weights != nil {
+				return nil, sceneErrorf("camera.aperture.path", "cannot be combined with width, height and weights")
+			}
+			aperture, err := NewImageApertureFromFile(spec.Path)
+			if err != nil {
+				return nil, &SceneError{Path: "camera.aperture.path", Err: err}
+			}
+			return aperture, nil
+		}
+		aperture, err := NewImageAperture(spec.Width, spec.Height, spec.Weights)
+		if err != nil {
+			return nil, &SceneError{Path: "camera.aperture", Err: err}
+		}
+		return aperture, nil
+	default:
+		return nil, sceneErrorf("camera.aperture.type", "unknown aperture %q (circle, polygon, image)", spec.Type)
+	}
+}
+
+func defaultFloat(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func defaultInt(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func defaultString(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func (l *sceneLoader) texture(raw json.RawMessage, path string) (TextureI, error) {
+	var spec textureJSON
+	if err := decodeStrict(raw, &spec, path); err != nil {
+		return nil, err
+	}
+	switch spec.Type {
+	case "solid":
+		c, err := spec.Color.vec3(path + ".color")
+		if err != nil {
+			return nil, err
+		}
+		return NewSolidColorTexture(Color(c)), nil
+	case "checker":
+		if spec.InvScale <= 0 {
+			return nil, sceneErrorf(path+".invScale", "must be positive, got %v", spec.InvScale)
+		}
+		even, err := spec.Even.vec3(path + ".even")
+		if err != nil {
+			return nil, err
+		}
+		odd, err := spec.Odd.vec3(path + ".odd")
+		if err != nil {
+			return nil, err
+		}
+		return NewCheckerTexture(spec.InvScale, Color(even), Color(odd)), nil
+	case "noise":
+		if spec.Scale <= 0 {
+			return nil, sceneErrorf(path+".scale", "must be positive, got %v", spec.Scale)
+		}
+		return NewNoiseTexture(spec.Scale), nil
+	case "image":
+		if spec.Path == "" {
+			return nil, sceneErrorf(path+".path", "missing")
+		}
+		texture, err := NewImageTexture(spec.Path)
+		if err != nil {
+			return nil, &SceneError{Path: path + ".path", Err: err}
+		}
+		return texture, nil
+	case "globe":
+		return NewGlobeTexture(), nil
+	default:
+		return nil, sceneErrorf(path+".type", "unknown texture %q (solid, checker, noise, image, globe)", spec.Type)
+	}
+}
+
+func (l *sceneLoader) textureRef(name, path string) (TextureI, error) {
+	texture, ok := l.textures[name]
+	if !ok {
+		return nil, sceneErrorf(path, "unknown texture %q", name)
+	}
+	return texture, nil
+}
+
+// textureOrColor 材质的颜色由纹理名称或纯色二者之一给出，纯色字段名为colorField
+func (l *sceneLoader) textureOrColor(name string, color vecJSON, path, colorField string) (TextureI, error) {
+	switch {
+	case name != "" && color != nil:
+		return nil, sceneErrorf(path, "texture and %s cannot both be set", colorField)
+	case name != "":
+		return l.textureRef(name, path+".texture")
+	}
+	c, err := color.vec3(path + "." + colorField)
+	if err != nil {
+		return nil, err
+	}
+	return NewSolidColorTexture(Color(c)), nil
+}
+
+func (l *sceneLoader) material(raw json.RawMessage, path string) (MaterialI, error) {
+	var spec materialJSON
+	if err := decodeStrict(raw, &spec, path); err != nil {
+		return nil, err
+	}
+	switch spec.Type {
+	case "lambertian":
+		material := LambertianReflectionMaterial{}
+		if spec.Texture != "" {
+			texture, err := l.textureRef(spec.Texture, path+".texture")
+			if err != nil {
+				return nil, err
+			}
+			material.Tex = texture
+		}
+		albedo, err := spec.Albedo.optionalVec3(path+".albedo", Vec3{0.5, 0.5, 0.5})
+		if err != nil {
+			return nil, err
+		}
+		material.Albedo = Color(albedo)
+		return material, nil
+	case "metal":
+		albedo, err := spec.Albedo.vec3(path + ".albedo")
+		if err != nil {
+			return nil, err
+		}
+		if spec.Fuzz < 0 || spec.Fuzz > 1 {
+			return nil, sceneErrorf(path+".fuzz", "must be in [0, 1], got %v", spec.Fuzz)
+		}
+		return MetalMaterial{Albedo: Color(albedo), Fuzz: spec.Fuzz}, nil
+	case "dielectric":
+		if spec.RefractionIndex <= 0 {
+			return nil, sceneErrorf(path+".refractionIndex", "must be positive, got %v", spec.RefractionIndex)
+		}
+		return DielectricMaterial{RefractionIndex: spec.RefractionIndex}, nil
+	case "diffuse_light":
+		texture, err := l.textureOrColor(spec.Texture, spec.Emit, path, "emit")
+		if err != nil {
+			return nil, err
+		}
+		return DiffuseLight{Tex: texture}, nil
+	case "isotropic":
+		texture, err := l.textureOrColor(spec.Texture, spec.Albedo, path, "albedo")
+		if err != nil {
+			return nil, err
+		}
+		return IsotropicMaterial{Tex: texture}, nil
+	default:
+		return nil, sceneErrorf(path+".type", "unknown material %q (lambertian, metal, dielectric, diffuse_light, isotropic)", spec.Type)
+	}
+}
+
+func (l *sceneLoader) materialRef(name, path string) (MaterialI, error) {
+	if name == "" {
+		if l.inCSG {
+			return nil, nil
+		}
+		return nil, sceneErrorf(path, "missing")
+	}
+	material, ok := l.materials[name]
+	if !ok {
+		return nil, sceneErrorf(path, "unknown material %q", name)
+	}
+	return material, nil
+}
+
+// checkFields 检查物体只包含其类型允许的字段
+func checkFields(raw json.RawMessage, objectType, path string) error {
+	allowed, ok := objectFields[objectType]
+	if !ok {
+		types := sortedKeys(objectFields)
+		return sceneErrorf(path+".type", "unknown object %q (%s)", objectType, strings.Join(types, ", "))
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return &SceneError{Path: path, Err: err}
+	}
+	for _, name := range sortedKeys(fields) {
+		found := false
+		for _, a := range allowed {
+			if a == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return sceneErrorf(path, "field %q is not allowed for %s objects", name, objectType)
+		}
+	}
+	return nil
+}
+
+func (l *sceneLoader) object(raw json.RawMessage, path string) (HittableItemI, error) {
+	var spec objectJSON
+	if err := decodeStrict(raw, &spec, path); err != nil {
+		return nil, err
+	}
+	if spec.Type == "" {
+		return nil, sceneErrorf(path+".type", "missing")
+	}
+	if err := checkFields(raw, spec.Type, path); err != nil {
+		return nil, err
+	}
+	switch spec.Type {
+	case "sphere":
+		center, err := spec.Center.vec3(path + ".center")
+		if err != nil {
+			return nil, err
+		}
+		if spec.Radius <= 0 {
+			return nil, sceneErrorf(path+".radius", "must be positive, got %v", spec.Radius)
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		sphere := NewSphere(Point(center), spec.Radius).WithMaterial(material)
+		if spec.MoveTo != nil {
+			end, err := spec.MoveTo.vec3(path + ".moveTo")
+			if err != nil {
+				return nil, err
+			}
+			startTime, endTime := 0.0, 1.0
+			if spec.MoveTime != nil {
+				if len(spec.MoveTime) != 2 || spec.MoveTime[1] < spec.MoveTime[0] {
+					return nil, sceneErrorf(path+".moveTime", "expected [start, end] with start <= end, got %v", spec.MoveTime)
+				}
+				startTime, endTime = spec.MoveTime[0], spec.MoveTime[1]
+			}
+			sphere.SetLinearMovement(Point(end), startTime, endTime)
+		} else if spec.MoveTime != nil {
+			return nil, sceneErrorf(path+".moveTime", "requires moveTo")
+		}
+		return sphere, nil
+	case "plane":
+		point, err := spec.Point.optionalVec3(path+".point", Vec3{})
+		if err != nil {
+			return nil, err
+		}
+		normal, err := spec.Normal.vec3(path + ".normal")
+		if err != nil {
+			return nil, err
+		}
+		if normal.LengthSquared() == 0 {
+			return nil, sceneErrorf(path+".normal", "must not be zero")
+		}
+		if spec.UVScale < 0 {
+			return nil, sceneErrorf(path+".uvScale", "must be positive, got %v", spec.UVScale)
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		plane := NewPlane(Point(point), normal).WithMaterial(material)
+		if spec.UVScale > 0 {
+			plane.WithUVScale(spec.UVScale)
+		}
+		return plane, nil
+	case "transform":
+		inner, err := l.innerObject(spec.Object, path)
+		if err != nil {
+			return nil, err
+		}
+		translate, err := spec.Translate.optionalVec3(path+".translate", Vec3{})
+		if err != nil {
+			return nil, err
+		}
+		rotate, err := spec.Rotate.optionalVec3(path+".rotate", Vec3{})
+		if err != nil {
+			return nil, err
+		}
+		scale, err := spec.Scale.optionalVec3(path+".scale", Vec3{1, 1, 1})
+		if err != nil {
+			return nil, err
+		}
+		if scale.X == 0 || scale.Y == 0 || scale.Z == 0 {
+			return nil, sceneErrorf(path+".scale", "components must not be zero, got %v", scale)
+		}
+		return NewInstance(inner, NewTransform(translate, rotate, scale)), nil
+	case "animated":
+		inner, err := l.innerObject(spec.Object, path)
+		if err != nil {
+			return nil, err
+		}
+		frames := make([]Keyframe, len(spec.Keyframes))
+		for i, key := range spec.Keyframes {
+			keyPath := fmt.Sprintf("%s.keyframes[%d]", path, i)
+			position, err := key.Position.vec3(keyPath + ".position")
+			if err != nil {
+				return nil, err
+			}
+			rotation, err := key.Rotation.optionalVec3(keyPath+".rotation", Vec3{})
+			if err != nil {
+				return nil, err
+			}
+			scale, err := key.Scale.optionalVec3(keyPath+".scale", Vec3{1, 1, 1})
+			if err != nil {
+				return nil, err
+			}
+			frames[i] = Keyframe{Time: key.Time, Position: Point(position), Rotation: rotation, Scale: scale}
+		}
+		track, err := NewAnimationTrack(Interpolation(defaultString(spec.Interpolation, string(LinearInterpolation))), frames...)
+		if err != nil {
+			return nil, &SceneError{Path: path, Err: err}
+		}
+		if spec.Pivot == nil {
+			return NewAnimated(inner, track), nil
+		}
+		pivot, err := spec.Pivot.vec3(path + ".pivot")
+		if err != nil {
+			return nil, err
+		}
+		return NewAnimatedWithPivot(inner, track, Point(pivot)), nil
+	case "quad":
+		corner, err := spec.Corner.vec3(path + ".corner")
+		if err != nil {
+			return nil, err
+		}
+		u, err := spec.U.vec3(path + ".u")
+		if err != nil {
+			return nil, err
+		}
+		v, err := spec.V.vec3(path + ".v")
+		if err != nil {
+			return nil, err
+		}
+		if u.Cross(v).LengthSquared() == 0 {
+			return nil, sceneErrorf(path+".v", "must not be parallel to u")
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		return NewQuad(Point(corner), u, v).WithMaterial(material), nil
+	case "constant_medium":
+		inner, err := l.innerObject(spec.Object, path)
+		if err != nil {
+			return nil, err
+		}
+		if spec.Density <= 0 {
+			return nil, sceneErrorf(path+".density", "must be positive, got %v", spec.Density)
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		isotropic, ok := material.(IsotropicMaterial)
+		if !ok {
+			return nil, sceneErrorf(path+".material", "material %q must be isotropic", spec.Material)
+		}
+		return NewConstantMediumWithTexture(inner, spec.Density, isotropic.Tex), nil
+	case "bvh":
+		if len(spec.Objects) == 0 {
+			return nil, sceneErrorf(path+".objects", "missing")
+		}
+		items := make([]HittableItemI, len(spec.Objects))
+		for i, raw := range spec.Objects {
+			itemPath := fmt.Sprintf("%s.objects[%d]", path, i)
+			item, err := l.object(raw, itemPath)
+			if err != nil {
+				return nil, err
+			}
+			if !item.GetBoundingBox().IsBounded() {
+				return nil, sceneErrorf(itemPath, "unbounded objects cannot be put in a bvh")
+			}
+			items[i] = item
+		}
+		return NewBVHNode(items), nil
+	case "box":
+		low, err := spec.Min.vec3(path + ".min")
+		if err != nil {
+			return nil, err
+		}
+		high, err := spec.Max.vec3(path + ".max")
+		if err != nil {
+			return nil, err
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		return NewBox(Point(low), Point(high)).WithMaterial(material), nil
+	case "disk":
+		center, err := spec.Center.vec3(path + ".center")
+		if err != nil {
+			return nil, err
+		}
+		normal, err := spec.Normal.vec3(path + ".normal")
+		if err != nil {
+			return nil, err
+		}
+		if normal.LengthSquared() == 0 {
+			return nil, sceneErrorf(path+".normal", "must not be zero")
+		}
+		if spec.Radius <= 0 {
+			return nil, sceneErrorf(path+".radius", "must be positive, got %v", spec.Radius)
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		return NewDisk(Point(center), normal, spec.Radius).WithMaterial(material), nil
+	case "cylinder", "cone":
+		center, err := spec.Center.optionalVec3(path+".center", Vec3{})
+		if err != nil {
+			return nil, err
+		}
+		if spec.Radius <= 0 {
+			return nil, sceneErrorf(path+".radius", "must be positive, got %v", spec.Radius)
+		}
+		if spec.Height <= 0 {
+			return nil, sceneErrorf(path+".height", "must be positive, got %v", spec.Height)
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		capped := spec.Capped == nil || *spec.Capped
+		if spec.Type == "cone" {
+			cone := NewCone(Point(center), spec.Radius, spec.Height).WithMaterial(material)
+			cone.Capped = capped
+			return cone, nil
+		}
+		cylinder := NewCylinder(Point(center), spec.Radius, spec.Height).WithMaterial(material)
+		cylinder.Capped = capped
+		return cylinder, nil
+	case "torus":
+		center, err := spec.Center.optionalVec3(path+".center", Vec3{})
+		if err != nil {
+			return nil, err
+		}
+		if spec.MajorRadius <= 0 {
+			return nil, sceneErrorf(path+".majorRadius", "must be positive, got %v", spec.MajorRadius)
+		}
+		if spec.MinorRadius <= 0 {
+			return nil, sceneErrorf(path+".minorRadius", "must be positive, got %v", spec.MinorRadius)
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		return NewTorus(Point(center), spec.MajorRadius, spec.MinorRadius).WithMaterial(material), nil
+	case "csg":
+		operation := CSGOperation(spec.Operation)
+		switch operation {
+		case CSGUnion, CSGIntersection, CSGDifference:
+		default:
+			return nil, sceneErrorf(path+".operation", "unknown operation %q (union, intersection, difference)", spec.Operation)
+		}
+		var material MaterialI
+		if spec.Material != "" {
+			var err error
+			if material, err = l.materialRef(spec.Material, path+".material"); err != nil {
+				return nil, err
+			}
+		}
+		inCSG := l.inCSG
+		l.inCSG = inCSG || material != nil
+		defer func() { l.inCSG = inCSG }()
+		var operands [2]HittableItemI
+		for i, raw := range []json.RawMessage{spec.A, spec.B} {
+			operandPath := path + "." + []string{"a", "b"}[i]
+			if raw == nil {
+				return nil, sceneErrorf(operandPath, "missing")
+			}
+			operand, err := l.object(raw, operandPath)
+			if err != nil {
+				return nil, err
+			}
+			operands[i] = operand
+		}
+		csg := NewCSG(operation, operands[0], operands[1])
+		if material != nil {
+			csg.WithMaterial(material)
+		}
+		return csg, nil
+	case "heightfield":
+		origin, err := spec.Origin.optionalVec3(path+".origin", Vec3{})
+		if err != nil {
+			return nil, err
+		}
+		size, err := spec.Size.vec3(path + ".size")
+		if err != nil {
+			return nil, err
+		}
+		material, err := l.materialRef(spec.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		var field *Heightfield
+		if spec.Path != "" {
+			if spec.Width != 0 || spec.Depth != 0 || spec.Heights != nil {
+				return nil, sceneErrorf(path+".path", "cannot be combined with width, depth and heights")
+			}
+			field, err = NewHeightfieldFromFile(spec.Path, Point(origin), size)
+			if err != nil {
+				return nil, &SceneError{Path: path + ".path", Err: err}
+			}
+		} else if field, err = NewHeightfield(spec.Heights, spec.Width, spec.Depth, Point(origin), size); err != nil {
+			return nil, &SceneError{Path: path, Err: err}
+		}
+		return field.WithMaterial(material), nil
+	}
+	return nil, sceneErrorf(path+".type", "unknown object %q", spec.Type)
+}
+
+func (l *sceneLoader) innerObject(raw json.RawMessage, path string) (HittableItemI, error) {
+	if raw == nil {
+		return nil, sceneErrorf(path+".object", "missing")
+	}
+	return l.object(raw, path+".object")
+}
+
+// sceneWriter 序列化过程中为纹理和材质分配名称
+type sceneWriter struct {
+	textures      map[TextureI]string
+	materials     map[MaterialI]string
+	textureSpecs  map[string]json.RawMessage
+	materialSpecs map[string]json.RawMessage
+	inCSG         bool // 正在写带材质的CSG中的物体，这些物体可以没有材质
+}
+
+// SaveSceneFile 将相机与场景保存为JSON文件
+func SaveSceneFile(path string, camera *Camera, settings RenderSettings) error {
+	var buf bytes.Buffer
+	if err := WriteScene(&buf, camera, settings); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// WriteScene 将内存中的相机与场景序列化为JSON，无法表示的对象（如自定义运动函数）会返回错误
+func WriteScene(w io.Writer, camera *Camera, settings RenderSettings) error {
+	writer := &sceneWriter{
+		textures:      map[TextureI]string{},
+		materials:     map[MaterialI]string{},
+		textureSpecs:  map[string]json.RawMessage{},
+		materialSpecs: map[string]json.RawMessage{},
+	}
+	cameraSpec, renderSpec, err := writer.camera(camera)
+	if err != nil {
+		return err
+	}
+	renderSpec.Threads = settings.Threads
+	renderSpec.Output = settings.Output
+	file := sceneJSON{Version: SceneFileVersion}
+	for i, item := range camera.world.HittableList {
+		raw, err := writer.object(item, fmt.Sprintf("objects[%d]", i))
+		if err != nil {
+			return err
+		}
+		file.Objects = append(file.Objects, raw)
+	}
+	if file.Camera, err = json.Marshal(cameraSpec); err != nil {
+		return err
+	}
+	if file.Render, err = json.Marshal(renderSpec); err != nil {
+		return err
+	}
+	if len(writer.textureSpecs) > 0 {
+		file.Textures = writer.textureSpecs
+	}
+	if len(writer.materialSpecs) > 0 {
+		file.Materials = writer.materialSpecs
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func (s *sceneWriter) camera(c *Camera) (cameraJSON, renderJSON, error) {
+	spec := cameraJSON{
+		LookFrom:          toVecJSON(Vec3(c.LookFrom)),
+		LookAt:            toVecJSON(Vec3(c.LookAt)),
+		AspectRatio:       c.AspectRatio,
+		VFov:              c.VFov,
+		DefocusAngle:      c.DefocusAngle,
+		FocusDist:         c.FocusDist,
+		OpticalVignetting: c.OpticalVignetting,
+	}
+	switch m := c.Model.(type) {
+	case nil, ThinLensModel, *ThinLensModel:
+	case OrthographicModel:
+		spec.Projection = &projectionJSON{Type: "orthographic", ViewHeight: m.ViewHeight}
+	case *OrthographicModel:
+		spec.Projection = &projectionJSON{Type: "orthographic", ViewHeight: m.ViewHeight}
+	case EquirectangularModel, *EquirectangularModel:
+		spec.Projection = &projectionJSON{Type: "equirectangular"}
+	case FisheyeModel:
+		spec.Projection = &projectionJSON{Type: "fisheye", FOV: m.FOV, Mapping: string(m.Mapping)}
+	case *FisheyeModel:
+		spec.Projection = &projectionJSON{Type: "fisheye", FOV: m.FOV, Mapping: string(m.Mapping)}
+	default:
+		return spec, renderJSON{}, sceneErrorf("camera.projection", "cannot serialize camera model %T", c.Model)
+	}
+	switch a := c.Aperture.(type) {
+	case nil, CircularAperture, *CircularAperture:
+	case PolygonAperture:
+		spec.Aperture = &apertureJSON{Type: "polygon", Blades: a.Blades, Rotation: a.Rotation}
+	case *PolygonAperture:
+		spec.Aperture = &apertureJSON{Type: "polygon", Blades: a.Blades, Rotation: a.Rotation}
+	case *ImageAperture:
+		if a.Path != "" {
+			spec.Aperture = &apertureJSON{Type: "image", Path: a.Path}
+			break
+		}
+		weights := make([]float64, len(a.cdf))
+		previous := 0.0
+		for i, c := range a.cdf {
+			weights[i] = c - previous
+			previous = c
+		}
+		spec.Aperture = &apertureJSON{Type: "image", Width: a.Width, Height: a.Height, Weights: weights}
+	default:
+		return spec, renderJSON{}, sceneErrorf("camera.aperture", "cannot serialize aperture %T", c.Aperture)
+	}
+	if c.ShutterOpen != 0 || c.ShutterClose != 1 || c.ShutterShape != BoxShutter || !c.MotionBlur {
+		motionBlur := c.MotionBlur
+		spec.Shutter = &shutterJSON{Open: c.ShutterOpen, Close: c.ShutterClose, Shape: string(c.ShutterShape), MotionBlur: &motionBlur}
+	}
+
+	if c.BackgroundColor != nil {
+		spec.Background = toVecJSON(Vec3(*c.BackgroundColor))
+	}
+
+	antialiased := c.IsAntialiased
+	render := renderJSON{
+		Width:           c.ImageWidth,
+		Height:          c.ImageHeight,
+		SamplesPerPixel: c.SamplesPerPixel,
+		MaxDepth:        c.MaxDepth,
+		Antialiased:     &antialiased,
+		BVH:             c.world.EnabledBVH,
+		AOV:             c.aovEnabled,
+	}
+	switch f := c.Filter.(type) {
+	case nil:
+	case *BoxFilter:
+		render.Filter = &filterJSON{Type: string(BoxFilterType), Radius: f.R}
+	case *TentFilter:
+		render.Filter = &filterJSON{Type: string(TentFilterType), Radius: f.R}
+	case *GaussianFilter:
+		render.Filter = &filterJSON{Type: string(GaussianFilterType), Radius: f.R}
+	case *MitchellFilter:
+		render.Filter = &filterJSON{Type: string(MitchellFilterType), Radius: f.R}
+	case *LanczosFilter:
+		render.Filter = &filterJSON{Type: string(LanczosFilterType), Radius: f.R}
+	default:
+		return spec, render, sceneErrorf("render.filter", "cannot serialize filter %T", c.Filter)
+	}
+	if d := c.Denoiser; d != nil {
+		render.Denoise = &denoiseJSON{
+			Strength:    d.Strength,
+			Iterations:  d.Iterations,
+			ColorSigma:  d.ColorSigma,
+			NormalSigma: d.NormalSigma,
+			AlbedoSigma: d.AlbedoSigma,
+		}
+	}
+	return spec, render, nil
+}
+
+func (s *sceneWriter) texture(t TextureI, path string) (string, error) {
+	if name, ok := s.textures[t]; ok {
+		return name, nil
+	}
+	var spec textureJSON
+	switch tex := t.(type) {
+	case *SolidColorTexture:
+		spec = textureJSON{Type: "solid", Color: toVecJSON(Vec3(tex.albedo))}
+	case *CheckerTexture:
+		even, evenOK := tex.even.(*SolidColorTexture)
+		odd, oddOK := tex.odd.(*SolidColorTexture)
+		if !evenOK || !oddOK {
+			return "", sceneErrorf(path, "cannot serialize checker texture with non-solid squares")
+		}
+		spec = textureJSON{Type: "checker", InvScale: tex.invScale, Even: toVecJSON(Vec3(even.albedo)), Odd: toVecJSON(Vec3(odd.albedo))}
+	case *NoiseTexture:
+		spec = textureJSON{Type: "noise", Scale: tex.scale}
+	case *ImageTexture:
+		if tex.Path == "" {
+			return "", sceneErrorf(path, "cannot serialize an image texture that was not loaded from a file")
+		}
+		spec = textureJSON{Type: "image", Path: tex.Path}
+	case *GlobeTexture:
+		spec = textureJSON{Type: "globe"}
+	default:
+		return "", sceneErrorf(path, "cannot serialize texture %T", t)
+	}
+	name := fmt.Sprintf("texture%d", len(s.textures)+1)
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	s.textures[t] = name
+	s.textureSpecs[name] = raw
+	return name, nil
+}
+
+func (s *sceneWriter) material(m MaterialI, path string) (string, error) {
+	if m == nil && s.inCSG {
+		return "", nil
+	}
+	if name, ok := s.materials[m]; ok {
+		return name, nil
+	}
+	var spec materialJSON
+	switch mat := m.(type) {
+	case LambertianReflectionMaterial:
+		spec = materialJSON{Type: "lambertian", Albedo: toVecJSON(Vec3(mat.Albedo))}
+		if mat.Tex != nil {
+			name, err := s.texture(mat.Tex, path+".texture")
+			if err != nil {
+				return "", err
+			}
+			spec.Texture = name
+		}
+	case MetalMaterial:
+		spec = materialJSON{Type: "metal", Albedo: toVecJSON(Vec3(mat.Albedo)), Fuzz: mat.Fuzz}
+	case DielectricMaterial:
+		spec = materialJSON{Type: "dielectric", RefractionIndex: mat.RefractionIndex}
+	case DiffuseLight:
+		spec = materialJSON{Type: "diffuse_light"}
+		if solid, ok := mat.Tex.(*SolidColorTexture); ok {
+			spec.Emit = toVecJSON(Vec3(solid.albedo))
+		} else {
+			name, err := s.texture(mat.Tex, path+".texture")
+			if err != nil {
+				return "", err
+			}
+			spec.Texture = name
+		}
+	case IsotropicMaterial:
+		spec = materialJSON{Type: "isotropic"}
+		if solid, ok := mat.Tex.(*SolidColorTexture); ok {
+			spec.Albedo = toVecJSON(Vec3(solid.albedo))
+		} else {
+			name, err := s.texture(mat.Tex, path+".texture")
+			if err != nil {
+				return "", err
+			}
+			spec.Texture = name
+		}
+	default:
+		return "", sceneErrorf(path, "cannot serialize material %T", m)
+	}
+	name := fmt.Sprintf("material%d", len(s.materials)+1)
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	s.materials[m] = name
+	s.materialSpecs[name] = raw
+	return name, nil
+}
+
+func (s *sceneWriter) object(item HittableItemI, path string) (json.RawMessage, error) {
+	var spec objectJSON
+	switch obj := item.(type) {
+	case *Sphere:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "sphere", Center: toVecJSON(Vec3(obj.Center)), Radius: obj.Radius, Material: material}
+		if obj.RemovableSetting.IsRemovable {
+			if obj.RemovableSetting.End == nil {
+				return nil, sceneErrorf(path, "cannot serialize a sphere with a custom moving function")
+			}
+			spec.MoveTo = toVecJSON(Vec3(*obj.RemovableSetting.End))
+			if start, end := obj.RemovableSetting.StartTime, obj.RemovableSetting.EndTime; start != 0 || end != 1 {
+				spec.MoveTime = []float64{start, end}
+			}
+		}
+	case *Plane:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "plane", Point: toVecJSON(Vec3(obj.Point)), Normal: toVecJSON(obj.Normal), Material: material}
+		if obj.UVScale != 1 {
+			spec.UVScale = obj.UVScale
+		}
+	case *Instance:
+		inner, err := s.object(obj.Item, path+".object")
+		if err != nil {
+			return nil, err
+		}
+		t := obj.Transform
+		spec = objectJSON{
+			Type:      "transform",
+			Translate: toVecJSON(t.Translation),
+			Rotate:    toVecJSON(t.Rotation.EulerDegrees()),
+			Scale:     toVecJSON(t.Scale),
+			Object:    inner,
+		}
+	case *Animated:
+		inner, err := s.object(obj.Item, path+".object")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{
+			Type:          "animated",
+			Interpolation: string(obj.Track.Interpolation),
+			Pivot:         toVecJSON(Vec3(obj.Pivot)),
+			Object:        inner,
+		}
+		for _, key := range obj.Track.Keyframes {
+			spec.Keyframes = append(spec.Keyframes, keyframeJSON{
+				Time:     key.Time,
+				Position: toVecJSON(Vec3(key.Position)),
+				Rotation: toVecJSON(key.Rotation),
+				Scale:    toVecJSON(key.Scale),
+			})
+		}
+	case *Quad:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "quad", Corner: toVecJSON(Vec3(obj.Q)), U: toVecJSON(obj.U), V: toVecJSON(obj.V), Material: material}
+	case *ConstantMedium:
+		material, err := s.material(obj.PhaseFunction, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		inner, err := s.object(obj.Boundary, path+".object")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "constant_medium", Density: -1 / obj.NegInvDensity, Material: material, Object: inner}
+	case *BVHNode:
+		// 按节点原样写出左右子树，加载时两个物体的节点不再排序，得到同样的层次结构
+		spec = objectJSON{Type: "bvh"}
+		children := obj.Children()
+		if obj.Left == obj.Right {
+			children = children[:1]
+		}
+		for i, child := range children {
+			inner, err := s.object(child, fmt.Sprintf("%s.objects[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			spec.Objects = append(spec.Objects, inner)
+		}
+	case *Box:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "box", Min: toVecJSON(Vec3(obj.Min)), Max: toVecJSON(Vec3(obj.Max)), Material: material}
+	case *Disk:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "disk", Center: toVecJSON(Vec3(obj.Center)), Normal: toVecJSON(obj.Normal), Radius: obj.Radius, Material: material}
+	case *Cylinder:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "cylinder", Center: toVecJSON(Vec3(obj.Center)), Radius: obj.Radius, Height: obj.Height, Material: material}
+		if !obj.Capped {
+			spec.Capped = &obj.Capped
+		}
+	case *Cone:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "cone", Center: toVecJSON(Vec3(obj.Center)), Radius: obj.Radius, Height: obj.Height, Material: material}
+		if !obj.Capped {
+			spec.Capped = &obj.Capped
+		}
+	case *Torus:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "torus", Center: toVecJSON(Vec3(obj.Center)), MajorRadius: obj.MajorRadius, MinorRadius: obj.MinorRadius, Material: material}
+	case *CSG:
+		spec = objectJSON{Type: "csg", Operation: string(obj.Operation)}
+		if obj.Material != nil {
+			material, err := s.material(obj.Material, path+".material")
+			if err != nil {
+				return nil, err
+			}
+			spec.Material = material
+		}
+		inCSG := s.inCSG
+		s.inCSG = inCSG || obj.Material != nil
+		a, err := s.object(obj.A, path+".a")
+		if err == nil {
+			spec.B, err = s.object(obj.B, path+".b")
+		}
+		s.inCSG = inCSG
+		if err != nil {
+			return nil, err
+		}
+		spec.A = a
+	case *Heightfield:
+		material, err := s.material(obj.Material, path+".material")
+		if err != nil {
+			return nil, err
+		}
+		spec = objectJSON{Type: "heightfield", Origin: toVecJSON(Vec3(obj.Origin)), Size: toVecJSON(obj.Size), Material: material}
+		if obj.Path != "" {
+			spec.Path = obj.Path
+		} else {
+			spec.Width, spec.Depth, spec.Heights = obj.Width, obj.Depth, obj.Heights
+		}
+	default:
+		return nil, sceneErrorf(path, "cannot serialize object %T", item)
+	}
+	return json.Marshal(spec)
+}
@@ -0,0 +1,365 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSceneJSON = `{
+  "version": 1,
+  "camera": {"lookFrom": [13, 2, 3], "lookAt": [0, 0, 0], "vfov": 20, "defocusAngle": 0.6,
+             "aperture": {"type": "polygon", "blades": 6}, "shutter": {"open": 0, "close": 0.5, "shape": "triangular"}},
+  "render": {"width": 64, "samplesPerPixel": 4, "maxDepth": 5, "bvh": true, "filter": {"type": "gaussian"}, "threads": 4, "output": "out.png"},
+  "textures": {"checker": {"type": "checker", "invScale": 0.32, "even": [0.2, 0.3, 0.1], "odd": [0.9, 0.9, 0.9]}},
+  "materials": {
+    "ground": {"type": "lambertian", "texture": "checker"},
+    "glass": {"type": "dielectric", "refractionIndex": 1.5},
+    "gold": {"type": "metal", "albedo": [0.8, 0.6, 0.2], "fuzz": 0.1}
+  },
+  "objects": [
+    {"type": "plane", "normal": [0, 1, 0], "uvScale": 4, "material": "ground"},
+    {"type": "sphere", "center": [0, 1, 0], "radius": 1, "material": "glass", "moveTo": [0, 1.5, 0], "moveTime": [0.25, 0.75]},
+    {"type": "transform", "translate": [4, 1, 0], "rotate": [0, 30, 0], "scale": [1, 2, 1],
+     "object": {"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "gold"}},
+    {"type": "animated", "interpolation": "bezier",
+     "keyframes": [{"time": 0, "position": [-4, 1, 0]}, {"time": 1, "position": [-4, 2, 0], "rotation": [0, 90, 0]}],
+     "object": {"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "gold"}}
+  ]
+}`
+
+func TestLoadScene(t *testing.T) {
+	camera, settings, err := LoadScene(strings.NewReader(testSceneJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Threads != 4 || settings.Output != "out.png" {
+		t.Errorf("settings = %+v", settings)
+	}
+	if camera.ImageWidth != 64 || camera.SamplesPerPixel != 4 || camera.MaxDepth != 5 || camera.VFov != 20 {
+		t.Errorf("camera = %dx%d spp %d depth %d vfov %v", camera.ImageWidth, camera.ImageHeight, camera.SamplesPerPixel, camera.MaxDepth, camera.VFov)
+	}
+	if _, ok := camera.Aperture.(*PolygonAperture); !ok {
+		t.Errorf("aperture = %T", camera.Aperture)
+	}
+	if camera.ShutterClose != 0.5 || camera.ShutterShape != TriangularShutter {
+		t.Errorf("shutter = %v %v %v", camera.ShutterOpen, camera.ShutterClose, camera.ShutterShape)
+	}
+	if !camera.world.EnabledBVH || len(camera.world.HittableList) != 4 {
+		t.Fatalf("world has %d objects, bvh %v", len(camera.world.HittableList), camera.world.EnabledBVH)
+	}
+	if len(camera.world.Unbounded) != 1 || camera.world.Unbounded[0] != camera.world.HittableList[0] {
+		t.Errorf("unbounded objects = %v", camera.world.Unbounded)
+	}
+	if sphere, ok := camera.world.HittableList[1].(*Sphere); !ok || sphere.RemovableSetting.StartTime != 0.25 || sphere.RemovableSetting.EndTime != 0.75 {
+		t.Errorf("objects[1] = %+v", camera.world.HittableList[1])
+	}
+	if _, ok := camera.world.HittableList[2].(*Instance); !ok {
+		t.Errorf("objects[2] = %T", camera.world.HittableList[2])
+	}
+	if _, ok := camera.world.HittableList[3].(*Animated); !ok {
+		t.Errorf("objects[3] = %T", camera.world.HittableList[3])
+	}
+}
+
+func TestSceneRoundTrip(t *testing.T) {
+	camera, settings, err := LoadScene(strings.NewReader(testSceneJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first bytes.Buffer
+	if err := WriteScene(&first, camera, settings); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, reloadedSettings, err := LoadScene(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatalf("reload: %v\n%s", err, first.String())
+	}
+	var second bytes.Buffer
+	if err := WriteScene(&second, reloaded, reloadedSettings); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("round trip changed the scene:\n%s\n---\n%s", first.String(), second.String())
+	}
+	// 两个金属球共用同一个材质
+	if strings.Count(first.String(), `"type": "metal"`) != 1 {
+		t.Errorf("materials were not deduplicated:\n%s", first.String())
+	}
+}
+
+// 《下一周》中的四边形、光源、参与介质、BVH和各种纹理都能保存后重新加载
+func TestSceneRoundTripNextWeekTypes(t *testing.T) {
+	texturePath := filepath.Join(t.TempDir(), "stripes.png")
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	f, err := os.Create(texturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	pathJSON, _ := json.Marshal(texturePath)
+	scene := `{
+  "version": 1,
+  "camera": {"lookFrom": [0, 0, 9], "lookAt": [0, 0, 0], "background": [0, 0, 0]},
+  "textures": {
+    "marble": {"type": "noise", "scale": 4},
+    "stripes": {"type": "image", "path": ` + string(pathJSON) + `},
+    "globe": {"type": "globe"}
+  },
+  "materials": {
+    "light": {"type": "diffuse_light", "emit": [4, 4, 4]},
+    "glow": {"type": "diffuse_light", "texture": "stripes"},
+    "smoke": {"type": "isotropic", "albedo": [0.2, 0.4, 0.9]},
+    "marble": {"type": "lambertian", "texture": "marble"},
+    "earth": {"type": "lambertian", "texture": "globe"}
+  },
+  "objects": [
+    {"type": "quad", "corner": [-1, 2, 0], "u": [2, 0, 0], "v": [0, 0, 2], "material": "light"},
+    {"type": "constant_medium", "density": 0.2, "material": "smoke",
+     "object": {"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "marble"}},
+    {"type": "bvh", "objects": [
+      {"type": "sphere", "center": [-2, 0, 0], "radius": 0.5, "material": "marble"},
+      {"type": "sphere", "center": [2, 0, 0], "radius": 0.5, "material": "earth"},
+      {"type": "quad", "corner": [0, -2, 0], "u": [1, 0, 0], "v": [0, 1, 0], "material": "glow"}
+    ]}
+  ]
+}`
+	camera, settings, err := LoadScene(strings.NewReader(scene))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := camera.world.HittableList[0].(*Quad); !ok {
+		t.Errorf("objects[0] = %T", camera.world.HittableList[0])
+	}
+	if medium, ok := camera.world.HittableList[1].(*ConstantMedium); !ok || medium.NegInvDensity != -5 {
+		t.Errorf("objects[1] = %+v", camera.world.HittableList[1])
+	}
+	if node, ok := camera.world.HittableList[2].(*BVHNode); !ok || node.Left == node.Right {
+		t.Errorf("objects[2] = %+v", camera.world.HittableList[2])
+	}
+	var first bytes.Buffer
+	if err := WriteScene(&first, camera, settings); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _, err := LoadScene(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatalf("reload: %v\n%s", err, first.String())
+	}
+	var second bytes.Buffer
+	if err := WriteScene(&second, reloaded, settings); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("round trip changed the scene:\n%s\n---\n%s", first.String(), second.String())
+	}
+	for _, want := range []string{`"type": "noise"`, `"path": ` + string(pathJSON), `"type": "globe"`, `"type": "isotropic"`, `"emit": [`} {
+		if !strings.Contains(first.String(), want) {
+			t.Errorf("scene file has no %s:\n%s", want, first.String())
+		}
+	}
+}
+
+// 解析几何体、盒子、CSG和高度场都能保存后重新加载，CSG带材质时其中的物体可以没有材质
+func TestSceneRoundTripShapes(t *testing.T) {
+	heightPath := filepath.Join(t.TempDir(), "height.png")
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(40 * i)
+	}
+	f, err := os.Create(heightPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	pathJSON, _ := json.Marshal(heightPath)
+	scene := `{
+  "version": 1,
+  "camera": {"lookFrom": [0, 2, 9], "lookAt": [0, 0, 0]},
+  "materials": {
+    "red": {"type": "lambertian", "albedo": [0.7, 0.2, 0.2]},
+    "gold": {"type": "metal", "albedo": [0.8, 0.6, 0.2], "fuzz": 0.1}
+  },
+  "objects": [
+    {"type": "box", "min": [0, 0, 0], "max": [1, 2, 1], "material": "red"},
+    {"type": "disk", "center": [0, 1, 0], "normal": [0, 1, 1], "radius": 0.7, "material": "gold"},
+    {"type": "cylinder", "center": [-3, 0, 0], "radius": 0.7, "height": 1.6, "capped": false, "material": "red"},
+    {"type": "cone", "center": [3, 0, 0], "radius": 0.8, "height": 1.8, "material": "red"},
+    {"type": "torus", "center": [0, 0.3, 2], "majorRadius": 0.8, "minorRadius": 0.3, "material": "gold"},
+    {"type": "csg", "operation": "difference", "material": "gold",
+     "a": {"type": "csg", "operation": "intersection",
+           "a": {"type": "sphere", "center": [0, 0, 0], "radius": 1},
+           "b": {"type": "box", "min": [-0.8, -0.8, -0.8], "max": [0.8, 0.8, 0.8]}},
+     "b": {"type": "transform", "rotate": [90, 0, 0], "object": {"type": "cylinder", "center": [0, -2, 0], "radius": 0.4, "height": 4}}},
+    {"type": "heightfield", "path": ` + string(pathJSON) + `, "origin": [-5, -1, -5], "size": [10, 1, 10], "material": "red"},
+    {"type": "heightfield", "width": 2, "depth": 2, "heights": [0, 0.5, 0.25, 1], "size": [1, 1, 1], "material": "gold"}
+  ]
+}`
+	camera, settings, err := LoadScene(strings.NewReader(scene))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cylinder, ok := camera.world.HittableList[2].(*Cylinder); !ok || cylinder.Capped {
+		t.Errorf("objects[2] = %+v", camera.world.HittableList[2])
+	}
+	if cone, ok := camera.world.HittableList[3].(*Cone); !ok || !cone.Capped {
+		t.Errorf("objects[3] = %+v", camera.world.HittableList[3])
+	}
+	if csg, ok := camera.world.HittableList[5].(*CSG); !ok || csg.Operation != CSGDifference || csg.Material == nil {
+		t.Errorf("objects[5] = %+v", camera.world.HittableList[5])
+	}
+	if field, ok := camera.world.HittableList[6].(*Heightfield); !ok || field.Width != 3 || field.Depth != 2 || field.Path != heightPath {
+		t.Errorf("objects[6] = %+v", camera.world.HittableList[6])
+	}
+	var first bytes.Buffer
+	if err := WriteScene(&first, camera, settings); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _, err := LoadScene(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatalf("reload: %v\n%s", err, first.String())
+	}
+	var second bytes.Buffer
+	if err := WriteScene(&second, reloaded, settings); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Errorf("round trip changed the scene:\n%s\n---\n%s", first.String(), second.String())
+	}
+	if !strings.Contains(first.String(), `"path": `+string(pathJSON)) || !strings.Contains(first.String(), `"heights": [`) {
+		t.Errorf("heightfields were not written by path and by value:\n%s", first.String())
+	}
+}
+
+// BVH按节点保存，重新加载后层次结构和物体顺序不变，再次保存的结果相同
+func TestSceneRoundTripBVH(t *testing.T) {
+	camera := NewCamera(Point{}, Point{Y: 5, Z: 10}, 1, 90, 8, 1, 1, true, 0, 1)
+	material := LambertianReflectionMaterial{Albedo: Color{X: 0.5, Y: 0.5, Z: 0.5}}
+	var spheres []HittableItemI
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			spheres = append(spheres, NewSphere(Point{X: float64(i), Y: float64((i * j) % 3), Z: float64(j)}, 0.4).WithMaterial(material))
+		}
+	}
+	camera.Add(NewBVHNode(spheres))
+	var first bytes.Buffer
+	if err := WriteScene(&first, camera, RenderSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _, err := LoadScene(bytes.NewReader(first.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var second bytes.Buffer
+	if err := WriteScene(&second, reloaded, RenderSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != second.String() {
+		t.Error("round trip changed the bvh")
+	}
+}
+
+func TestSceneRoundTripImageSize(t *testing.T) {
+	// 220/(220/100)在浮点下略小于100，只保存宽度和宽高比会截断成99行
+	camera := NewCamera(Point{}, Point{Z: 1}, 2, 90, 8, 1, 1, true, 0, 1)
+	camera.SetImageSize(220, 100)
+	camera.Add(NewSphere(Point{}, 1).WithMaterial(LambertianReflectionMaterial{Albedo: Color{X: 0.5, Y: 0.5, Z: 0.5}}))
+	var buf bytes.Buffer
+	if err := WriteScene(&buf, camera, RenderSettings{}); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, _, err := LoadScene(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("reload: %v\n%s", err, buf.String())
+	}
+	if reloaded.ImageWidth != 220 || reloaded.ImageHeight != 100 {
+		t.Errorf("image size = %dx%d, want 220x100", reloaded.ImageWidth, reloaded.ImageHeight)
+	}
+}
+
+func TestLoadSceneErrors(t *testing.T) {
+	base := `"camera": {"lookFrom": [0, 0, 1], "lookAt": [0, 0, 0]}, "materials": {"m": {"type": "lambertian"}}`
+	tests := []struct {
+		scene string
+		want  string
+	}{
+		{`{"camera": {}}`, "version: missing"},
+		{`{"version": 2}`, "version: unsupported version 2"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "sphere", "center": [0, 0, 0], "radius": -1, "material": "m"}]}`,
+			"objects[0].radius: must be positive"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "sphere", "center": [0, 0], "radius": 1, "material": "m"}]}`,
+			"objects[0].center: expected [x, y, z], got 2 numbers"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "x"}]}`,
+			`objects[0].material: unknown material "x"`},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "m", "rotate": [0, 0, 0]}]}`,
+			`objects[0]: field "rotate" is not allowed for sphere objects`},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "m", "moveTo": [1, 0, 0], "moveTime": [1]}]}`,
+			"objects[0].moveTime: expected [start, end]"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "transform", "object": {"type": "cube"}}]}`,
+			`objects[0].object.type: unknown object "cube"`},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "sphere", "radius": "big"}]}`,
+			"objects[0].radius: expected float64, got JSON string"},
+		{`{"version": 1, "camera": {"lookFrom": [0, 0, 1], "lookAt": [0, 0, 0], "fov": 40}, "objects": []}`,
+			`camera: unknown field "fov"`},
+		{`{"version": 1, "camera": {"lookFrom": [0, 0, 1], "lookAt": [0, 0, 0]}, "render": {"filter": {"type": "blur"}}, "objects": []}`,
+			"render.filter: "},
+		{`{"version": 1, "camera": {"lookFrom": [0, 0, 1], "lookAt": [0, 0, 0]}, "render": {"height": -1}, "objects": []}`,
+			"render.height: must be positive, got -1"},
+		{`{"version": 1, "camera": {"lookFrom": [0, 0, 1], "lookAt": [0, 0, 0], "aperture": {"type": "image", "path": "a.png", "width": 1}}, "objects": []}`,
+			"camera.aperture.path: cannot be combined with width, height and weights"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "constant_medium", "density": 1, "material": "m", "object": {"type": "sphere", "center": [0, 0, 0], "radius": 1, "material": "m"}}]}`,
+			`objects[0].material: material "m" must be isotropic`},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "bvh", "objects": [{"type": "plane", "normal": [0, 1, 0], "material": "m"}]}]}`,
+			"objects[0].objects[0]: unbounded objects cannot be put in a bvh"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "quad", "corner": [0, 0, 0], "u": [1, 0, 0], "v": [2, 0, 0], "material": "m"}]}`,
+			"objects[0].v: must not be parallel to u"},
+		{`{"version": 1, "camera": {"lookFrom": [0, 0, 1], "lookAt": [0, 0, 0]}, "materials": {"l": {"type": "diffuse_light", "emit": [1, 1, 1], "texture": "t"}}, "objects": []}`,
+			"materials.l: texture and emit cannot both be set"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "csg", "operation": "xor", "a": {}, "b": {}}]}`,
+			`objects[0].operation: unknown operation "xor"`},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "csg", "operation": "union", "a": {"type": "sphere", "center": [0, 0, 0], "radius": 1}, "b": {}}]}`,
+			"objects[0].a.material: missing"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "heightfield", "path": "h.png", "width": 2, "size": [1, 1, 1], "material": "m"}]}`,
+			"objects[0].path: cannot be combined with width, depth and heights"},
+		{`{"version": 1, ` + base + `, "objects": [{"type": "torus", "majorRadius": 1, "material": "m"}]}`,
+			"objects[0].minorRadius: must be positive, got 0"},
+		{"{\n  \"version\": 1,\n  \"camera\": {,\n}", "invalid JSON at line 3, column 14"},
+		{`{"version": 1, "camera": {"lookFrom": [0, 0, 1]`, "invalid JSON: unexpected end of input"},
+	}
+	for _, test := range tests {
+		_, _, err := LoadScene(strings.NewReader(test.scene))
+		if err == nil {
+			t.Errorf("%s: expected error %q", test.scene, test.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s:\n got %q\nwant %q", test.scene, err.Error(), test.want)
+		}
+	}
+}
+
+func TestWriteSceneUnsupported(t *testing.T) {
+	camera := NewCamera(Point{}, Point{Z: 1}, 1, 90, 8, 1, 1, true, 0, 1)
+	sphere := NewSphere(Point{}, 1).WithMaterial(LambertianReflectionMaterial{Albedo: Color{X: 0.5, Y: 0.5, Z: 0.5}})
+	sphere.RemovableSetting = RemovableSetting{IsRemovable: true, MovingFunc: func(t float64) Point { return Point{X: t} }}
+	camera.Add(sphere)
+	var buf bytes.Buffer
+	err := WriteScene(&buf, camera, RenderSettings{})
+	if err == nil || !strings.Contains(err.Error(), "objects[0]: cannot serialize a sphere with a custom moving function") {
+		t.Errorf("err = %v", err)
+	}
+}
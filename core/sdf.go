@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+// SDF 有向距离函数：点到表面的距离，物体外为正、物体内为负
+type SDF func(p Vec3) float64
+
+/*
+有向距离场物体：在包围盒内用球体追踪（sphere tracing）求交。
+从射线进入包围盒的位置开始，每一步前进当前点的距离值（乘以StepScale），
+距离小于Epsilon时认为击中，走出包围盒或超过MaxSteps步则未击中。
+起点在物体内时按距离的绝对值前进，找到离开物体的交点。
+起点位于表面的误差带（距离绝对值不超过2*Epsilon）内时，例如从该物体表面出发的折射或反射射线，
+先以Epsilon为步长走出误差带，再按所在一侧判断寻找进入还是离开的交点，否则会立刻再次击中出发的表面。
+距离函数不是精确距离（如扭曲、分形的距离估计）时，需要把StepScale调小以免穿过表面。
+法线由四面体差分估计梯度得到。
+UV按法线方向的球面坐标计算。
+*/
+type SDFObject struct {
+	Distance  SDF
+	MaxSteps  int
+	Epsilon   float64
+	StepScale float64
+	Material  MaterialI
+	AABB      *AABB
+}
+
+// NewSDFObject box为距离场的包围盒，表面必须完全位于其中，不能为nil
+func NewSDFObject(distance SDF, box *AABB) (*SDFObject, error) {
+	if distance == nil {
+		return nil, fmt.Errorf("sdf: nil distance function")
+	}
+	if box == nil {
+		return nil, fmt.Errorf("sdf: a bounding box is required, the surface of a distance field cannot be bounded automatically")
+	}
+	return &SDFObject{
+		Distance:  distance,
+		MaxSteps:  256,
+		Epsilon:   1e-4,
+		StepScale: 1,
+		AABB:      box,
+	}, nil
+}
+
+func (sdf *SDFObject) WithMaterial(mat MaterialI) *SDFObject {
+	sdf.Material = mat
+	return sdf
+}
+
+// WithStepScale 设置步长系数，取(0,1]
+func (sdf *SDFObject) WithStepScale(scale float64) *SDFObject {
+	sdf.StepScale = scale
+	return sdf
+}
+
+func (sdf *SDFObject) GetMaterial() MaterialI {
+	return sdf.Material
+}
+
+func (sdf *SDFObject) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		sdf.AABB = aabb
+	}
+}
+
+func (sdf *SDFObject) GetBoundingBox() *AABB {
+	return sdf.AABB
+}
+
+func (sdf *SDFObject) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	boxT, inBox := sdf.AABB.Clip(&ray, rayT)
+	if !inBox {
+		return false, hitRecord
+	}
+	length := ray.Direction.Length()
+	t := boxT.Min
+	steps := 0
+	// 走出起点所在的误差带
+	start := sdf.Distance(Vec3(ray.At(t)))
+	for ; math.Abs(start) <= 2*sdf.Epsilon; steps++ {
+		if steps >= sdf.MaxSteps || t >= boxT.Max {
+			return false, hitRecord
+		}
+		t += sdf.Epsilon / length
+		start = sdf.Distance(Vec3(ray.At(t)))
+	}
+	// 起点的符号决定寻找进入还是离开物体的交点
+	sign := 1.0
+	if start < 0 {
+		sign = -1
+	}
+	for ; steps < sdf.MaxSteps; steps++ {
+		if t >= boxT.Max {
+			return false, hitRecord
+		}
+		p := Vec3(ray.At(t))
+		distance := sign * sdf.Distance(p)
+		if distance < sdf.Epsilon {
+			if !rayT.Surrounds(t) {
+				return false, hitRecord
+			}
+			normal := sdf.normal(p)
+			hitRecord.Time = t
+			hitRecord.HitPoint = Point(p)
+			hitRecord.setFaceNormal(ray, normal)
+			hitRecord.U, hitRecord.V = sphereUV(normal)
+			hitRecord.Material = sdf.Material
+			return true, hitRecord
+		}
+		t += math.Max(distance*sdf.StepScale, sdf.Epsilon) / length
+	}
+	return false, hitRecord
+}
+
+// normal 四面体差分：在四个方向 (±h,±h,±h) 上采样，加权求和得到梯度方向
+func (sdf *SDFObject) normal(p Vec3) Vec3 {
+	h := sdf.Epsilon
+	var gradient Vec3
+	for _, k := range [4]Vec3{{1, -1, -1}, {-1, -1, 1}, {-1, 1, -1}, {1, 1, 1}} {
+		gradient = gradient.Add(k.MultiplicationNum(sdf.Distance(p.Add(k.MultiplicationNum(h)))))
+	}
+	if gradient.NearZero() {
+		return Vec3{Y: 1}
+	}
+	return gradient.Normalize()
+}
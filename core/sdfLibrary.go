@@ -0,0 +1,162 @@
+package core
+
+import "math"
+
+/*
+常用的有向距离函数与组合运算（参考 Inigo Quilez 的距离函数整理）。
+基本形状都以原点为中心，通过 Translate、Scale 等运算放置。
+并、交、差和平移、缩放、重复保持精确距离（重复要求形状不超出单个周期），
+平滑组合、扭曲、分形得到的是距离的估计，渲染时需要适当调小 SDFObject.StepScale。
+*/
+
+// SphereSDF 半径为radius的球
+func SphereSDF(radius float64) SDF {
+	return func(p Vec3) float64 {
+		return p.Length() - radius
+	}
+}
+
+// BoxSDF 半边长为halfSize的长方体
+func BoxSDF(halfSize Vec3) SDF {
+	return func(p Vec3) float64 {
+		q := Vec3{math.Abs(p.X) - halfSize.X, math.Abs(p.Y) - halfSize.Y, math.Abs(p.Z) - halfSize.Z}
+		outside := Vec3{math.Max(q.X, 0), math.Max(q.Y, 0), math.Max(q.Z, 0)}
+		return outside.Length() + math.Min(math.Max(q.X, math.Max(q.Y, q.Z)), 0)
+	}
+}
+
+// TorusSDF 绕Y轴的圆环，环心圆半径major，管半径minor
+func TorusSDF(major, minor float64) SDF {
+	return func(p Vec3) float64 {
+		return math.Hypot(math.Hypot(p.X, p.Z)-major, p.Y) - minor
+	}
+}
+
+// CapsuleSDF 线段ab加半径radius的胶囊
+func CapsuleSDF(a, b Vec3, radius float64) SDF {
+	ab := b.Sub(a)
+	return func(p Vec3) float64 {
+		ap := p.Sub(a)
+		h := math.Max(0, math.Min(1, ap.Dot(ab)/ab.Dot(ab)))
+		return ap.Sub(ab.MultiplicationNum(h)).Length() - radius
+	}
+}
+
+// MandelbulbSDF power次Mandelbulb分形的距离估计，以Y轴为极轴，iterations越大细节越多，常用power为8
+func MandelbulbSDF(power float64, iterations int) SDF {
+	return func(p Vec3) float64 {
+		z := p
+		dr := 1.0
+		r := 0.0
+		for range iterations {
+			r = z.Length()
+			if r > 2 {
+				break
+			}
+			// 球坐标下做幂运算：半径取power次方，两个角度乘以power
+			theta := math.Acos(math.Max(-1, math.Min(1, z.Y/math.Max(r, 1e-12)))) * power
+			phi := math.Atan2(z.Z, z.X) * power
+			dr = math.Pow(r, power-1)*power*dr + 1
+			zr := math.Pow(r, power)
+			z = Vec3{
+				X: zr * math.Sin(theta) * math.Cos(phi),
+				Y: zr * math.Cos(theta),
+				Z: zr * math.Sin(theta) * math.Sin(phi),
+			}.Add(p)
+		}
+		if r == 0 {
+			return 0
+		}
+		return 0.5 * math.Log(r) * r / dr
+	}
+}
+
+// Union 并
+func (a SDF) Union(b SDF) SDF {
+	return func(p Vec3) float64 {
+		return math.Min(a(p), b(p))
+	}
+}
+
+// Intersection 交
+func (a SDF) Intersection(b SDF) SDF {
+	return func(p Vec3) float64 {
+		return math.Max(a(p), b(p))
+	}
+}
+
+// Difference 差 a-b
+func (a SDF) Difference(b SDF) SDF {
+	return func(p Vec3) float64 {
+		return math.Max(a(p), -b(p))
+	}
+}
+
+// SmoothUnion 平滑并，k为过渡区域的宽度
+func (a SDF) SmoothUnion(b SDF, k float64) SDF {
+	return func(p Vec3) float64 {
+		da, db := a(p), b(p)
+		h := math.Max(0, math.Min(1, 0.5+0.5*(db-da)/k))
+		return mix(db, da, h) - k*h*(1-h)
+	}
+}
+
+// SmoothIntersection 平滑交
+func (a SDF) SmoothIntersection(b SDF, k float64) SDF {
+	return func(p Vec3) float64 {
+		da, db := a(p), b(p)
+		h := math.Max(0, math.Min(1, 0.5-0.5*(db-da)/k))
+		return mix(db, da, h) + k*h*(1-h)
+	}
+}
+
+// SmoothDifference 平滑差 a-b
+func (a SDF) SmoothDifference(b SDF, k float64) SDF {
+	return a.SmoothIntersection(func(p Vec3) float64 { return -b(p) }, k)
+}
+
+// Translate 平移offset
+func (a SDF) Translate(offset Vec3) SDF {
+	return func(p Vec3) float64 {
+		return a(p.Sub(offset))
+	}
+}
+
+// Scale 均匀缩放s倍
+func (a SDF) Scale(s float64) SDF {
+	return func(p Vec3) float64 {
+		return a(p.Div(s)) * s
+	}
+}
+
+// Round 表面向外扩展radius，使棱角变圆
+func (a SDF) Round(radius float64) SDF {
+	return func(p Vec3) float64 {
+		return a(p) - radius
+	}
+}
+
+// Repeat 按周期period在空间中无限重复，某个分量为0时该轴不重复
+func (a SDF) Repeat(period Vec3) SDF {
+	wrap := func(x, c float64) float64 {
+		if c == 0 {
+			return x
+		}
+		return x - c*math.Round(x/c)
+	}
+	return func(p Vec3) float64 {
+		return a(Vec3{wrap(p.X, period.X), wrap(p.Y, period.Y), wrap(p.Z, period.Z)})
+	}
+}
+
+// Twist 绕Y轴扭曲，每单位高度旋转k弧度
+func (a SDF) Twist(k float64) SDF {
+	return func(p Vec3) float64 {
+		sin, cos := math.Sincos(k * p.Y)
+		return a(Vec3{cos*p.X - sin*p.Z, p.Y, sin*p.X + cos*p.Z})
+	}
+}
+
+func mix(x, y, a float64) float64 {
+	return x*(1-a) + y*a
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSDFObject(t *testing.T) {
+	box := NewAABBFromPoints(Point{X: -1.1, Y: -1.1, Z: -1.1}, Point{X: 1.1, Y: 1.1, Z: 1.1})
+	traced, err := NewSDFObject(SphereSDF(1), box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sphere := NewSphere(Point{}, 1)
+	rays := []Ray{
+		NewRay(Point{Z: 5}, Vec3{Z: -1}),
+		NewRay(Point{X: 3, Y: 2, Z: 4}, Vec3{X: -3, Y: -2.2, Z: -4.1}),
+		NewRay(Point{X: -4, Y: 0.9}, Vec3{X: 1}),
+		NewRay(Point{X: 0.2, Y: -0.3}, Vec3{X: 1, Y: 0.5, Z: -0.2}), // 从内部射出
+		NewRay(Point{X: 2, Y: 5}, Vec3{Y: -1}),                      // 未击中
+	}
+	for i, ray := range rays {
+		wantHit, want := sphere.Hittable(ray, NewInterval(1e-5, math.Inf(1)))
+		hit, got := traced.Hittable(ray, NewInterval(1e-5, math.Inf(1)))
+		if hit != wantHit {
+			t.Errorf("ray %d: hit = %v, want %v", i, hit, wantHit)
+			continue
+		}
+		if !hit {
+			continue
+		}
+		if math.Abs(got.Time-want.Time) > 1e-3 || got.Normal.Sub(want.Normal).Length() > 1e-3 || got.FrontFace != want.FrontFace {
+			t.Errorf("ray %d: t=%v normal=%v front=%v, want t=%v normal=%v front=%v", i, got.Time, got.Normal, got.FrontFace, want.Time, want.Normal, want.FrontFace)
+		}
+		if got.HitPoint != ray.At(got.Time) {
+			t.Errorf("ray %d: hit point %v is not on the ray at t=%v", i, got.HitPoint, got.Time)
+		}
+	}
+	if hit, _ := traced.Hittable(NewRay(Point{Z: 5}, Vec3{Z: -1}), NewInterval(1e-5, 3)); hit {
+		t.Error("hit beyond rayT.Max")
+	}
+}
+
+// 玻璃球内的折射射线从入射点出发，应当在另一侧离开，而不是再次击中入射面
+func TestSDFObjectEnterAndExit(t *testing.T) {
+	box := NewAABBFromPoints(Point{X: -1.1, Y: -1.1, Z: -1.1}, Point{X: 1.1, Y: 1.1, Z: 1.1})
+	traced, err := NewSDFObject(SphereSDF(1), box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, direction := range []Vec3{{Z: -1}, {X: 0.05, Y: -0.03, Z: -1}} {
+		hit, entry := traced.Hittable(NewRay(Point{Z: 5}, direction), NewInterval(1e-5, math.Inf(1)))
+		if !hit || !entry.FrontFace {
+			t.Fatalf("ray %d: entry hit=%v front=%v", i, hit, entry.FrontFace)
+		}
+		inside := NewRay(entry.HitPoint, Vec3{X: 0.1, Z: -1})
+		hit, exit := traced.Hittable(inside, NewInterval(1e-5, math.Inf(1)))
+		// 单位球面上的点P沿单位方向d前进，另一个交点在t=-2P·d处
+		want := -2 * Vec3(entry.HitPoint).Dot(inside.Direction)
+		if !hit || math.Abs(exit.Time-want) > 1e-3 || exit.FrontFace {
+			t.Fatalf("ray %d: exit hit=%v t=%v front=%v, want t=%v front=false", i, hit, exit.Time, exit.FrontFace, want)
+		}
+		// 离开后继续前进不会再击中同一个表面
+		if hit, again := traced.Hittable(NewRay(exit.HitPoint, inside.Direction), NewInterval(1e-5, math.Inf(1))); hit {
+			t.Errorf("ray %d: hit the exit surface again at t=%v", i, again.Time)
+		}
+	}
+}
+
+func TestSDFLibrary(t *testing.T) {
+	near := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	box := BoxSDF(Vec3{X: 1, Y: 2, Z: 3})
+	near("box inside", box(Vec3{}), -1)
+	near("box face", box(Vec3{Y: 4}), 2)
+	near("box corner", box(Vec3{X: 4, Y: 6, Z: 3}), 5)
+	near("torus", TorusSDF(2, 0.5)(Vec3{X: 3}), 0.5)
+	near("capsule", CapsuleSDF(Vec3{}, Vec3{Y: 2}, 0.5)(Vec3{X: 1, Y: 1}), 0.5)
+
+	a := SphereSDF(1)
+	b := SphereSDF(1).Translate(Vec3{X: 1.5})
+	p := Vec3{X: 0.75}
+	near("union", a.Union(b)(p), -0.25)
+	near("intersection", a.Intersection(b)(p), -0.25)
+	near("difference", a.Difference(b)(p), 0.25)
+	if smooth := a.SmoothUnion(b, 0.5)(p); smooth >= -0.25 {
+		t.Errorf("smooth union %v should be below the plain union", smooth)
+	}
+	near("smooth union far from the blend", a.SmoothUnion(b, 0.5)(Vec3{X: -2}), 1)
+	near("scale", SphereSDF(1).Scale(2)(Vec3{X: 3}), 1)
+	near("round", box.Round(0.5)(Vec3{Y: 4}), 1.5)
+
+	repeated := SphereSDF(0.5).Repeat(Vec3{X: 2})
+	near("repeat", repeated(Vec3{X: 10.25}), repeated(Vec3{X: 0.25}))
+	near("repeat unrepeated axis", repeated(Vec3{Y: 3}), 2.5)
+
+	slab := BoxSDF(Vec3{X: 1, Y: 10, Z: 0.1})
+	twisted := slab.Twist(math.Pi / 2)
+	near("twist at y=0", twisted(Vec3{X: 0.5}), slab(Vec3{X: 0.5}))
+	// 高度为1处旋转了90°，原来X方向的长边转到了Z方向
+	if twisted(Vec3{Y: 1, Z: 0.5}) >= 0 || twisted(Vec3{Y: 1, X: 0.5}) <= 0 {
+		t.Error("twist did not rotate the box")
+	}
+
+	bulb := MandelbulbSDF(8, 10)
+	if bulb(Vec3{}) > 0 || bulb(Vec3{X: 3}) < 1 {
+		t.Errorf("mandelbulb distances %v %v", bulb(Vec3{}), bulb(Vec3{X: 3}))
+	}
+	object, err := NewSDFObject(bulb, NewAABBFromPoints(Point{X: -1.3, Y: -1.3, Z: -1.3}, Point{X: 1.3, Y: 1.3, Z: 1.3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hit, record := object.Hittable(NewRay(Point{X: 0.1, Y: 0.2, Z: 5}, Vec3{Z: -1}), NewInterval(1e-5, math.Inf(1))); !hit || record.Time < 3.7 || record.Time > 5 {
+		t.Errorf("mandelbulb hit %v at t=%v", hit, record.Time)
+	}
+}
+
+// 距离场无法自动求包围盒，缺少包围盒时构造报错，SetBoundingBox(nil)保留原来的包围盒
+func TestSDFObjectBoundingBox(t *testing.T) {
+	if _, err := NewSDFObject(SphereSDF(1), nil); err == nil {
+		t.Error("expected an error for a nil bounding box")
+	}
+	box := NewAABBFromPoints(Point{X: -1, Y: -1, Z: -1}, Point{X: 1, Y: 1, Z: 1})
+	object, err := NewSDFObject(SphereSDF(1), box)
+	if err != nil {
+		t.Fatal(err)
+	}
+	object.SetBoundingBox(nil)
+	if object.GetBoundingBox() != box {
+		t.Fatalf("bounding box = %v", object.GetBoundingBox())
+	}
+	world := Scenes{HittableAABB: NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval())}
+	world.Add(object)
+	if len(world.HittableList) != 1 {
+		t.Errorf("scene has %d objects", len(world.HittableList))
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"fmt"
+	"math"
+)
+
+/*
+动画序列渲染：
+每一帧调用 Scene 回调，由调用方根据帧号和时刻摆放相机（如环绕的转台路径）、构建场景或设置关键帧物体，
+渲染器负责设置该帧的快门、渲染并保存为 name_0001.ppm 这样的编号文件（格式由 Format 指定），最后可选地合成GIF预览。
+时刻以秒为单位：第n帧的快门在 n/FPS 打开，持续 ShutterAngle/360/FPS 秒，关键帧轨道应使用相同的时间单位。
+*/
+
+// FrameSceneFunc 返回第frame帧（时刻time秒）要渲染的相机，相机中应已添加好场景
+type FrameSceneFunc func(frame int, time float64) (*Camera, error)
+
+// AnimationSequence 动画序列设置
+type AnimationSequence struct {
+	Start, End   int     // 帧范围[Start, End]
+	FPS          float64 // 帧率
+	ShutterAngle float64 // 快门角度（度），180为电影常用值，0为关闭运动模糊
+	ShutterShape ShutterShape
+	Workers      int    // 渲染协程数，<=1为单线程
+	Buffer       int    // 多线程渲染的结果通道大小
+	Format       string // 帧文件的扩展名，取值见 utils.ImageFormats
+	GIF          bool
+	Scene        FrameSceneFunc
+}
+
+func NewAnimationSequence(start, end int, fps float64, scene FrameSceneFunc) *AnimationSequence {
+	return &AnimationSequence{
+		Start:        start,
+		End:          end,
+		FPS:          fps,
+		ShutterAngle: 180,
+		ShutterShape: BoxShutter,
+		Workers:      1,
+		Buffer:       1000000,
+		Format:       ".ppm",
+		GIF:          true,
+		Scene:        scene,
+	}
+}
+
+// FrameName 第frame帧的输出文件名（不含扩展名）
+func FrameName(name string, frame int) string {
+	return fmt.Sprintf("%s_%04d", name, frame)
+}
+
+// Render 依次渲染每一帧并保存，开启GIF时额外输出 name.gif，返回各帧文件名（不含扩展名）
+func (s *AnimationSequence) Render(name string) ([]string, error) {
+	if s.End < s.Start {
+		return nil, fmt.Errorf("frame range [%d, %d] is empty", s.Start, s.End)
+	}
+	if s.FPS <= 0 {
+		return nil, fmt.Errorf("fps must be positive, got %v", s.FPS)
+	}
+	if s.Scene == nil {
+		return nil, fmt.Errorf("no scene callback")
+	}
+	format, err := utils.ImageFormat(FrameName(name, s.Start) + s.Format)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var frames [][]utils.Pixel
+	width, height := 0, 0
+	for frame := s.Start; frame <= s.End; frame++ {
+		time := float64(frame) / s.FPS
+		camera, err := s.Scene(frame, time)
+		if err != nil {
+			return names, fmt.Errorf("frame %d: %w", frame, err)
+		}
+		if err := camera.SetShutter(time, time+s.ShutterAngle/360/s.FPS, s.ShutterShape); err != nil {
+			return names, fmt.Errorf("frame %d: %w", frame, err)
+		}
+		camera.EnabledMotionBlur(s.ShutterAngle > 0)
+		if frame == s.Start {
+			width, height = camera.ImageWidth, camera.ImageHeight
+		} else if camera.ImageWidth != width || camera.ImageHeight != height {
+			return names, fmt.Errorf("frame %d is %dx%d, previous frames are %dx%d", frame, camera.ImageWidth, camera.ImageHeight, width, height)
+		}
+
+		var colors []Color
+		if s.Workers > 1 {
+			colors = camera.MultithreadedRenderToColors(s.Workers, s.Buffer)
+		} else {
+			colors = camera.RenderToColors()
+		}
+		frameName := FrameName(name, frame)
+		if err := camera.SaveImage(frameName+format, colors); err != nil {
+			return names, fmt.Errorf("frame %d: %w", frame, err)
+		}
+		names = append(names, frameName)
+		if s.GIF {
+			frames = append(frames, Colors2Pixels(colors))
+		}
+	}
+	if s.GIF {
+		delay := int(math.Round(100 / s.FPS))
+		if err := utils.SaveGIF(name+".gif", width, height, frames, max(delay, 1)); err != nil {
+			return names, err
+		}
+	}
+	return names, nil
+}
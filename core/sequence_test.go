@@ -0,0 +1,65 @@
+package core
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnimationSequence(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "turntable")
+	var times []float64
+	sequence := NewAnimationSequence(1, 3, 10, func(frame int, time float64) (*Camera, error) {
+		times = append(times, time)
+		camera := NewCamera(Point{0, 0, 0}, Point{0, 0, 3}, 1, 60, 8, 2, 2, true, 0, 3)
+		camera.Add(NewSphere(Point{X: time}, 0.5).WithMaterial(LambertianReflectionMaterial{Albedo: Color{0.5, 0.5, 0.5}}))
+		return camera, nil
+	})
+	names, err := sequence.Render(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 || names[0] != name+"_0001" || len(times) != 3 || times[2] != 0.3 {
+		t.Fatalf("names = %v, times = %v", names, times)
+	}
+	for _, frame := range names {
+		if _, err := os.Stat(frame + ".ppm"); err != nil {
+			t.Error(err)
+		}
+	}
+	f, err := os.Open(name + ".gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 3 || anim.Delay[0] != 10 {
+		t.Errorf("gif has %d frames with delay %v", len(anim.Image), anim.Delay)
+	}
+
+	sequence.End = 1
+	sequence.GIF = false
+	sequence.Format = ".png"
+	if _, err := sequence.Render(name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name + "_0001.png"); err != nil {
+		t.Error(err)
+	}
+
+	times = nil
+	sequence.Format = ".exr"
+	if _, err := sequence.Render(name); err == nil || len(times) != 0 {
+		t.Errorf("unsupported format: err = %v, rendered %d frames", err, len(times))
+	}
+
+	sequence.Format = ".ppm"
+	sequence.End = 0
+	if _, err := sequence.Render(name); err == nil {
+		t.Error("expected error for empty frame range")
+	}
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"fmt"
+	"math/rand"
+)
+
+/*
+快门：
+每条相机射线在快门打开期间[ShutterOpen, ShutterClose]内取一个时刻，运动物体据此计算位置，从而产生运动模糊。
+快门形状描述快门在打开过程中的透光比例：
+Box 为瞬间全开全关，时刻均匀分布；Triangular 为逐渐打开再逐渐关闭，时刻集中在中间，模糊拖尾更柔和。
+逐帧渲染动画时，将第n帧的快门设置为[n/fps, n/fps + 快门角度/360/fps]，即可获得物理一致的模糊长度。
+*/
+
+type ShutterShape string
+
+const (
+	BoxShutter        ShutterShape = "box"
+	TriangularShutter ShutterShape = "triangular"
+)
+
+// SetShutter 设置快门开闭时刻与形状，并开启运动模糊
+func (c *Camera) SetShutter(open, close float64, shape ShutterShape) error {
+	if close < open {
+		return fmt.Errorf("shutter closes (%v) before it opens (%v)", close, open)
+	}
+	switch shape {
+	case BoxShutter, TriangularShutter:
+	default:
+		return fmt.Errorf("unknown shutter shape %q", shape)
+	}
+	c.ShutterOpen = open
+	c.ShutterClose = close
+	c.ShutterShape = shape
+	c.MotionBlur = true
+	return nil
+}
+
+// EnabledMotionBlur 关闭时所有射线都取快门打开的时刻
+func (c *Camera) EnabledMotionBlur(enabled bool) {
+	c.MotionBlur = enabled
+}
+
+// sampleTime 按快门形状在快门打开期间取一个时刻
+func (c *Camera) sampleTime(rng *rand.Rand) float64 {
+	if !c.MotionBlur || c.ShutterClose <= c.ShutterOpen {
+		return c.ShutterOpen
+	}
+	var s float64
+	switch c.ShutterShape {
+	case TriangularShutter:
+		s = (utils.RandomFrom(rng) + utils.RandomFrom(rng)) / 2 // 两个均匀分布之和为三角分布
+	default:
+		s = utils.RandomFrom(rng)
+	}
+	return c.ShutterOpen + s*(c.ShutterClose-c.ShutterOpen)
+}
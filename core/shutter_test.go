@@ -0,0 +1,86 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShutterTimes(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 1, 60, 10, 1, 4, true, 0, 1)
+	for _, shape := range []ShutterShape{BoxShutter, TriangularShutter} {
+		if err := camera.SetShutter(2, 3, shape); err != nil {
+			t.Fatal(err)
+		}
+		sum, middle := 0.0, 0
+		const n = 20000
+		for k := 0; k < n; k++ {
+			tm := camera.GetRay(5, 5).Time()
+			if tm < 2 || tm > 3 {
+				t.Fatalf("%s: time %v outside the shutter interval", shape, tm)
+			}
+			sum += tm
+			if tm > 2.25 && tm < 2.75 {
+				middle++
+			}
+		}
+		if mean := sum / n; math.Abs(mean-2.5) > 0.02 {
+			t.Errorf("%s: mean time %v", shape, mean)
+		}
+		// 均匀分布中间一半占50%，三角分布占75%
+		want := map[ShutterShape]float64{BoxShutter: 0.5, TriangularShutter: 0.75}[shape]
+		if got := float64(middle) / n; math.Abs(got-want) > 0.02 {
+			t.Errorf("%s: %v of the samples in the middle half, want %v", shape, got, want)
+		}
+	}
+	camera.EnabledMotionBlur(false)
+	if tm := camera.GetRay(5, 5).Time(); tm != 2 {
+		t.Errorf("motion blur disabled but time = %v", tm)
+	}
+	if err := camera.SetShutter(1, 0, BoxShutter); err == nil {
+		t.Error("expected error for inverted shutter")
+	}
+}
+
+// 快门在[2,3]时，运动球体必须停留在包围盒内，开启BVH后仍能被击中
+func TestShutterMovingSphere(t *testing.T) {
+	camera := NewCamera(Point{}, Point{Z: -5}, 1, 60, 10, 1, 4, true, 0, 1)
+	if err := camera.SetShutter(2, 3, BoxShutter); err != nil {
+		t.Fatal(err)
+	}
+	legacy := NewSphere(Point{X: -1}, 0.3)
+	legacy.SetUniformLinearMovement(Point{X: -1, Y: 1})
+	timed := NewSphere(Point{X: 1}, 0.3)
+	timed.SetLinearMovement(Point{X: 1, Y: 1}, 2, 3)
+	camera.Add(legacy, timed, NewSphere(Point{Z: 3}, 0.3))
+	camera.EnabledBVH(true)
+
+	tests := []struct {
+		name   string
+		target Point
+		time   float64
+		want   HittableItemI
+	}{
+		{"legacy mover rests at its end point", Point{X: -1, Y: 1}, 2.5, legacy},
+		{"legacy mover left its start point", Point{X: -1}, 2.5, nil},
+		{"timed mover halfway", Point{X: 1, Y: 0.5}, 2.5, timed},
+		{"timed mover at shutter open", Point{X: 1}, 2, timed},
+		{"timed mover at shutter close", Point{X: 1, Y: 1}, 3, timed},
+	}
+	for _, test := range tests {
+		ray := NewRayWithTime(Point{X: test.target.X, Y: test.target.Y, Z: -5}, Vec3{Z: 1}, test.time)
+		hit, record := camera.world.Hit(ray, NewInterval(1e-5, math.Inf(1)))
+		linearHit, linearRecord := camera.world.HitAnything(ray, NewInterval(1e-5, math.Inf(1)))
+		if hit != linearHit || record.Object != linearRecord.Object {
+			t.Errorf("%s: bvh and linear search disagree", test.name)
+		}
+		if test.want == nil {
+			if hit {
+				t.Errorf("%s: unexpected hit", test.name)
+			}
+			continue
+		}
+		if !hit || record.Object != test.want {
+			t.Errorf("%s: hit %v object %v", test.name, hit, record.Object)
+		}
+	}
+}
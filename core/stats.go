@@ -0,0 +1,101 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+/*
+渲染统计：
+每个渲染协程持有自己的计数器，计数器挂在相机射线上并随散射射线、实例变换后的局部射线一起传递，
+BVH节点和图元求交时直接在射线携带的计数器上累加，不需要任何同步。
+协程结束时再用原子操作把计数合并到相机的统计中，整个过程无锁。
+*/
+
+// RenderStats 一次渲染的计数与各阶段耗时
+type RenderStats struct {
+	PrimaryRays    int64 // 相机射线数
+	TotalRays      int64 // 追踪的射线总数（相机射线与所有散射射线）
+	BVHNodeVisits  int64 // 访问的BVH节点数
+	PrimitiveTests int64 // 图元求交测试次数
+
+	BVHBuild time.Duration // 构建BVH
+	Trace    time.Duration // 追踪射线
+	Resolve  time.Duration // 胶片与辅助缓冲解析
+	Denoise  time.Duration // 降噪
+	Save     time.Duration // 保存图片
+}
+
+// AveragePathLength 每条相机射线平均产生的射线数（含自身）
+func (s RenderStats) AveragePathLength() float64 {
+	if s.PrimaryRays == 0 {
+		return 0
+	}
+	return float64(s.TotalRays) / float64(s.PrimaryRays)
+}
+
+// Total 各阶段耗时之和
+func (s RenderStats) Total() time.Duration {
+	return s.BVHBuild + s.Trace + s.Resolve + s.Denoise + s.Save
+}
+
+func (s RenderStats) String() string {
+	perRay := func(n int64) float64 {
+		if s.TotalRays == 0 {
+			return 0
+		}
+		return float64(n) / float64(s.TotalRays)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "primary rays:     %d\n", s.PrimaryRays)
+	fmt.Fprintf(&b, "total rays:       %d\n", s.TotalRays)
+	fmt.Fprintf(&b, "avg path length:  %.2f\n", s.AveragePathLength())
+	fmt.Fprintf(&b, "BVH node visits:  %d (%.1f per ray)\n", s.BVHNodeVisits, perRay(s.BVHNodeVisits))
+	fmt.Fprintf(&b, "primitive tests:  %d (%.1f per ray)\n", s.PrimitiveTests, perRay(s.PrimitiveTests))
+	if s.Trace > 0 {
+		fmt.Fprintf(&b, "ray throughput:   %.2f Mrays/s\n", float64(s.TotalRays)/s.Trace.Seconds()/1e6)
+	}
+	round := func(d time.Duration) time.Duration { return d.Round(time.Millisecond) }
+	fmt.Fprintf(&b, "time:             bvh %v, trace %v, resolve %v, denoise %v, save %v (total %v)",
+		round(s.BVHBuild), round(s.Trace), round(s.Resolve), round(s.Denoise), round(s.Save), round(s.Total()))
+	return b.String()
+}
+
+// rayCounters 单个渲染协程的计数器，nil表示不统计
+type rayCounters struct {
+	primaryRays, rays, bvhNodeVisits, primitiveTests int64
+}
+
+func (c *rayCounters) primaryRay() {
+	if c != nil {
+		c.primaryRays++
+	}
+}
+
+func (c *rayCounters) ray() {
+	if c != nil {
+		c.rays++
+	}
+}
+
+func (c *rayCounters) bvhNodeVisit() {
+	if c != nil {
+		c.bvhNodeVisits++
+	}
+}
+
+func (c *rayCounters) primitiveTest() {
+	if c != nil {
+		c.primitiveTests++
+	}
+}
+
+// flush 原子地把计数合并到统计中，可以被多个协程同时调用
+func (c *rayCounters) flush(stats *RenderStats) {
+	atomic.AddInt64(&stats.PrimaryRays, c.primaryRays)
+	atomic.AddInt64(&stats.TotalRays, c.rays)
+	atomic.AddInt64(&stats.BVHNodeVisits, c.bvhNodeVisits)
+	atomic.AddInt64(&stats.PrimitiveTests, c.primitiveTests)
+}
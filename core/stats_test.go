@@ -0,0 +1,49 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func statsTestCamera() *Camera {
+	camera := NewCamera(Point{}, Point{Z: 5}, 1, 40, 12, 3, 5, true, 0, 5)
+	camera.Add(
+		NewSphere(Point{Y: -100.5}, 100).WithMaterial(LambertianReflectionMaterial{Albedo: Color{X: 0.5, Y: 0.5, Z: 0.5}}),
+		NewSphere(Point{}, 0.5).WithMaterial(MetalMaterial{Albedo: Color{X: 0.8, Y: 0.8, Z: 0.8}}),
+		NewSphere(Point{X: 1}, 0.5).WithMaterial(DielectricMaterial{RefractionIndex: 1.5}),
+	)
+	return camera
+}
+
+func TestRenderStats(t *testing.T) {
+	camera := statsTestCamera()
+	camera.RenderToColors()
+	stats := camera.Stats()
+	primary := int64(12 * 12 * 3)
+	if stats.PrimaryRays != primary {
+		t.Errorf("primary rays = %d, want %d", stats.PrimaryRays, primary)
+	}
+	if stats.TotalRays <= stats.PrimaryRays || stats.AveragePathLength() <= 1 || stats.AveragePathLength() > 5 {
+		t.Errorf("total rays = %d, avg path length %v", stats.TotalRays, stats.AveragePathLength())
+	}
+	// 线性求交时每条射线测试全部3个球
+	if stats.PrimitiveTests != 3*stats.TotalRays {
+		t.Errorf("primitive tests = %d, want %d", stats.PrimitiveTests, 3*stats.TotalRays)
+	}
+	if stats.BVHNodeVisits != 0 {
+		t.Errorf("bvh node visits = %d without a bvh", stats.BVHNodeVisits)
+	}
+	if stats.Trace <= 0 || !strings.Contains(stats.String(), "primary rays:     432") {
+		t.Errorf("trace %v\n%s", stats.Trace, stats)
+	}
+
+	camera.EnabledBVH(true)
+	camera.MultithreadedRenderToColors(4, 100)
+	stats = camera.Stats()
+	if stats.PrimaryRays != primary {
+		t.Errorf("multithreaded primary rays = %d, want %d", stats.PrimaryRays, primary)
+	}
+	if stats.BVHNodeVisits < stats.TotalRays {
+		t.Errorf("bvh node visits = %d for %d rays", stats.BVHNodeVisits, stats.TotalRays)
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import "fmt"
+
+/*
+立体相机组：
+以 Camera 为中心眼，左右眼沿相机u轴各偏移瞳距的一半。
+ToeIn（内旋）：两眼分别转向会聚点，简单但会产生垂直视差。
+OffAxis（离轴平行）：两眼朝向不变，平移视口使两个视锥在会聚距离处重合，没有垂直视差，是推荐的方式。
+会聚距离处的物体视差为0，显示在屏幕平面上；更近的物体出屏，更远的物体入屏。
+*/
+
+type StereoMode string
+
+const (
+	ToeInStereo   StereoMode = "toe-in"
+	OffAxisStereo StereoMode = "off-axis"
+)
+
+type StereoLayout string
+
+const (
+	SideBySideLayout StereoLayout = "side-by-side" // 左眼在左
+	OverUnderLayout  StereoLayout = "over-under"   // 左眼在上
+	AnaglyphLayout   StereoLayout = "anaglyph"     // 红青立体，左眼取红色通道
+)
+
+// StereoRig 立体相机组
+type StereoRig struct {
+	Camera              *Camera // 中心眼，提供场景与所有渲染设置
+	InterocularDistance float64 // 瞳距（世界单位）
+	ConvergenceDistance float64 // 会聚距离，<=0时使用相机的FocusDist
+	Mode                StereoMode
+	Layout              StereoLayout
+}
+
+func NewStereoRig(camera *Camera, interocularDistance, convergenceDistance float64, mode StereoMode, layout StereoLayout) (*StereoRig, error) {
+	switch mode {
+	case ToeInStereo, OffAxisStereo:
+	default:
+		return nil, fmt.Errorf("unknown stereo mode %q", mode)
+	}
+	switch layout {
+	case SideBySideLayout, OverUnderLayout, AnaglyphLayout:
+	default:
+		return nil, fmt.Errorf("unknown stereo layout %q", layout)
+	}
+	return &StereoRig{
+		Camera:              camera,
+		InterocularDistance: interocularDistance,
+		ConvergenceDistance: convergenceDistance,
+		Mode:                mode,
+		Layout:              layout,
+	}, nil
+}
+
+func (s *StereoRig) convergence() float64 {
+	if s.ConvergenceDistance > 0 {
+		return s.ConvergenceDistance
+	}
+	return s.Camera.FocusDist
+}
+
+// Eyes 生成左右眼相机，与中心眼共享场景
+func (s *StereoRig) Eyes() (left, right *Camera) {
+	return s.eye(-0.5), s.eye(0.5)
+}
+
+// eye side为-0.5（左）或0.5（右）
+func (s *StereoRig) eye(side float64) *Camera {
+	center := s.Camera
+	eye := *center
+	offset := center.u.MultiplicationNum(side * s.InterocularDistance)
+	position := Point(Vec3(center.CameraCenter).Add(offset))
+	convergence := s.convergence()
+	switch s.Mode {
+	case ToeInStereo:
+		target := Point(Vec3(center.CameraCenter).Sub(center.w.MultiplicationNum(convergence)))
+		eye.Orient(position, target)
+	default:
+		// 平移眼睛，再平移焦平面上的视口，使两眼视口在会聚距离处的投影与中心眼重合
+		eye.CameraCenter = position
+		eye.LookFrom = position
+		eye.LookAt = Point(Vec3(center.LookAt).Add(offset))
+		shift := offset.MultiplicationNum(1 - center.FocusDist/convergence)
+		eye.ViewportUpperLeft = center.ViewportUpperLeft.Add(shift)
+		eye.Pixel00Local = center.Pixel00Local.Add(shift)
+	}
+	return &eye
+}
+
+// Render 单线程依次渲染左右眼，合成后按扩展名保存到path
+func (s *StereoRig) Render(path string) error {
+	left, right := s.Eyes()
+	return s.save(path, left.RenderToColors(), right.RenderToColors())
+}
+
+// MultithreadedRender 多线程依次渲染左右眼，合成后按扩展名保存到path
+func (s *StereoRig) MultithreadedRender(path string, maxWorkers, buffer int) error {
+	left, right := s.Eyes()
+	return s.save(path, left.MultithreadedRenderToColors(maxWorkers, buffer), right.MultithreadedRenderToColors(maxWorkers, buffer))
+}
+
+func (s *StereoRig) save(path string, left, right []Color) error {
+	colors, width, height := s.Compose(left, right)
+	return SaveColors(path, width, height, colors)
+}
+
+// Compose 按布局合成左右眼图像，返回合成后的颜色与尺寸
+func (s *StereoRig) Compose(left, right []Color) (colors []Color, width, height int) {
+	w, h := s.Camera.ImageWidth, s.Camera.ImageHeight
+	switch s.Layout {
+	case SideBySideLayout:
+		colors = make([]Color, 0, 2*w*h)
+		for j := 0; j < h; j++ {
+			colors = append(colors, left[j*w:(j+1)*w]...)
+			colors = append(colors, right[j*w:(j+1)*w]...)
+		}
+		return colors, 2 * w, h
+	case OverUnderLayout:
+		colors = make([]Color, 0, 2*w*h)
+		colors = append(colors, left...)
+		colors = append(colors, right...)
+		return colors, w, 2 * h
+	default:
+		colors = make([]Color, w*h)
+		for i := range colors {
+			colors[i] = Color{left[i].X, right[i].Y, right[i].Z}
+		}
+		return colors, w, h
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import "testing"
+
+func TestStereoEyesConverge(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 2, 60, 40, 1, 4, true, 0, 2)
+	for _, mode := range []StereoMode{ToeInStereo, OffAxisStereo} {
+		rig, err := NewStereoRig(camera, 0.2, 5, mode, SideBySideLayout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		left, right := rig.Eyes()
+		if d := Vec3(right.CameraCenter).Sub(Vec3(left.CameraCenter)); d.Sub(Vec3{X: 0.2}).Length() > 1e-9 {
+			t.Fatalf("%s: eye separation = %v", mode, d)
+		}
+		// 图像中心的射线都应穿过会聚点(0,0,-5)
+		for _, eye := range []*Camera{left, right} {
+			r := eye.GetRayWithOffset(20, 10, Vec3{X: -0.5, Y: -0.5})
+			s := -5 / r.Direction.Z
+			p := r.At(s)
+			if Vec3(p).Sub(Vec3{0, 0, -5}).Length() > 1e-9 {
+				t.Errorf("%s: center ray misses convergence point, reaches %v", mode, p)
+			}
+		}
+	}
+	if _, err := NewStereoRig(camera, 0.2, 5, "cross", SideBySideLayout); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestStereoCompose(t *testing.T) {
+	camera := NewCamera(Point{0, 0, -1}, Point{0, 0, 0}, 2, 60, 2, 1, 4, true, 0, 2)
+	left := []Color{{1, 0, 0}, {1, 0, 0}}
+	right := []Color{{0, 1, 1}, {0, 0.5, 0.5}}
+	for layout, want := range map[StereoLayout][3]int{
+		SideBySideLayout: {4, 1, 4},
+		OverUnderLayout:  {2, 2, 4},
+		AnaglyphLayout:   {2, 1, 2},
+	} {
+		rig, _ := NewStereoRig(camera, 0.1, 0, OffAxisStereo, layout)
+		colors, w, h := rig.Compose(left, right)
+		if w != want[0] || h != want[1] || len(colors) != want[2] {
+			t.Errorf("%s: got %dx%d (%d pixels)", layout, w, h, len(colors))
+		}
+	}
+	rig, _ := NewStereoRig(camera, 0.1, 0, OffAxisStereo, AnaglyphLayout)
+	colors, _, _ := rig.Compose(left, right)
+	if colors[1] != (Color{1, 0.5, 0.5}) {
+		t.Errorf("anaglyph pixel = %v", colors[1])
+	}
+}
@@ -1,6 +1,13 @@
 package core
 
-import "math"
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+)
 
 type TextureI interface {
 	Value(u, v float64, p Point) Color
@@ -42,3 +49,108 @@ func (c CheckerTexture) Value(u, v float64, p Point) Color {
 		return c.odd.Value(u, v, p)
 	}
 }
+
+/*
+图像纹理：按UV坐标在图片中取色，U从左到右，V从下到上。
+图片中存储的是伽马校正后的sRGB值，取色时转换回线性空间。
+*/
+type ImageTexture struct {
+	Width, Height int
+	Path          string  // 图片路径，由 NewImageTexture 设置，场景文件按路径保存纹理
+	pixels        []Color // 按行存储的线性颜色
+}
+
+// NewImageTexture 读取PNG或JPEG图片作为纹理
+func NewImageTexture(path string) (*ImageTexture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	texture := NewImageTextureFromImage(img)
+	texture.Path = path
+	return texture, nil
+}
+
+func NewImageTextureFromImage(img image.Image) *ImageTexture {
+	bounds := img.Bounds()
+	texture := &ImageTexture{
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		pixels: make([]Color, 0, bounds.Dx()*bounds.Dy()),
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			texture.pixels = append(texture.pixels, Color{
+				X: math.Pow(float64(r)/0xffff, 2.2),
+				Y: math.Pow(float64(g)/0xffff, 2.2),
+				Z: math.Pow(float64(b)/0xffff, 2.2),
+			})
+		}
+	}
+	return texture
+}
+
+func (t ImageTexture) Value(u, v float64, p Point) Color {
+	if len(t.pixels) == 0 {
+		// 没有图片数据时返回青色以便发现问题
+		return Color{0, 1, 1}
+	}
+	unit := Interval{0, 1}
+	u = unit.Clamp(u)
+	v = 1.0 - unit.Clamp(v) // 图片的行从上往下
+	i := min(int(u*float64(t.Width)), t.Width-1)
+	j := min(int(v*float64(t.Height)), t.Height-1)
+	return t.pixels[j*t.Width+i]
+}
+
+// NoiseTexture Perlin噪声纹理，用湍流扰动正弦条纹的相位得到大理石效果
+type NoiseTexture struct {
+	noise *Perlin
+	scale float64
+}
+
+func NewNoiseTexture(scale float64) *NoiseTexture {
+	return &NoiseTexture{noise: NewPerlin(), scale: scale}
+}
+
+func (n NoiseTexture) Value(u, v float64, p Point) Color {
+	return Color(Vec3{0.5, 0.5, 0.5}.MultiplicationNum(1 + math.Sin(n.scale*p.Z+10*n.noise.Turbulence(p, 7))))
+}
+
+// GlobeTexture 程序生成的地球纹理，用分形Perlin噪声划分海洋和陆地，两极覆盖冰盖。
+// 噪声在球面方向上取值而不是在(u,v)平面上，因此经线接缝和两极都没有拉伸
+type GlobeTexture struct {
+	noise *Perlin
+}
+
+func NewGlobeTexture() *GlobeTexture {
+	return &GlobeTexture{noise: NewPerlin()}
+}
+
+func (g GlobeTexture) Value(u, v float64, p Point) Color {
+	// 由球面纹理坐标还原单位方向，是球体sphereUV的逆变换
+	theta, phi := v*math.Pi, u*2*math.Pi-math.Pi
+	direction := Vec3{X: math.Sin(theta) * math.Cos(phi), Y: -math.Cos(theta), Z: -math.Sin(theta) * math.Sin(phi)}
+	if math.Abs(direction.Y) > 0.9 {
+		return Color{X: 0.85, Y: 0.88, Z: 0.9}
+	}
+	height, amplitude := 0.0, 1.0
+	q := direction.MultiplicationNum(2)
+	for range 5 {
+		height += amplitude * g.noise.Noise(Point(q))
+		amplitude *= 0.5
+		q = q.MultiplicationNum(2)
+	}
+	if height < 0.05 {
+		depth := math.Min(1, (0.05-height)*2) // 离海岸越远海水越深
+		return Color{X: 0.02, Y: 0.1 - 0.06*depth, Z: 0.35 - 0.15*depth}
+	}
+	elevation := math.Min(1, (height-0.05)*2) // 从沿海的绿色过渡到内陆的褐色
+	return Color{X: 0.1 + 0.3*elevation, Y: 0.3, Z: 0.08 + 0.1*elevation}
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSphereUV(t *testing.T) {
+	tests := []struct {
+		p    Vec3
+		u, v float64
+	}{
+		{Vec3{X: 1}, 0.5, 0.5},
+		{Vec3{Y: 1}, 0.5, 1},
+		{Vec3{Y: -1}, 0.5, 0},
+		{Vec3{Z: 1}, 0.25, 0.5},
+		{Vec3{X: -1}, 0, 0.5},
+		{Vec3{Z: -1}, 0.75, 0.5},
+	}
+	for _, test := range tests {
+		u, v := sphereUV(test.p)
+		if math.Abs(u-test.u) > 1e-9 && !(test.u == 0 && math.Abs(u-1) < 1e-9) || math.Abs(v-test.v) > 1e-9 {
+			t.Errorf("%v: got (%v,%v), want (%v,%v)", test.p, u, v, test.u, test.v)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+圆环：中心Center，绕Y轴，环心圆半径MajorRadius（R），管半径MinorRadius（r）。
+隐式方程 (x²+y²+z²+R²-r²)² = 4R²(x²+z²)，代入射线得到关于t的四次方程。
+为减小数值误差，先把射线起点移到包围盒入口附近、方向归一化后再求解，最后用牛顿迭代修正根。
+外法线为 p - R·normalize(x, 0, z)。其他朝向通过 Instance 旋转得到。
+UV：U为绕Y轴的角度，V为绕管截面的角度，均归一化到[0,1)。
+*/
+type Torus struct {
+	Center      Point
+	MajorRadius float64
+	MinorRadius float64
+	Material    MaterialI
+	AABB        *AABB
+}
+
+func NewTorus(center Point, majorRadius, minorRadius float64) *Torus {
+	torus := &Torus{
+		Center:      center,
+		MajorRadius: majorRadius,
+		MinorRadius: minorRadius,
+	}
+	torus.SetBoundingBox(nil)
+	return torus
+}
+
+func (torus *Torus) WithMaterial(mat MaterialI) *Torus {
+	torus.Material = mat
+	return torus
+}
+
+func (torus *Torus) GetMaterial() MaterialI {
+	return torus.Material
+}
+
+func (torus *Torus) SetBoundingBox(aabb *AABB) {
+	if aabb != nil {
+		torus.AABB = aabb
+		return
+	}
+	extent := Vec3{X: torus.MajorRadius + torus.MinorRadius, Y: torus.MinorRadius, Z: torus.MajorRadius + torus.MinorRadius}
+	torus.AABB = NewAABBFromPoints(Point(Vec3(torus.Center).Sub(extent)), Point(Vec3(torus.Center).Add(extent)))
+}
+
+func (torus *Torus) GetBoundingBox() *AABB {
+	return torus.AABB
+}
+
+func (torus *Torus) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	ray.counters.primitiveTest()
+	// 包围盒求交得到射线进入的参数，同时排除大部分未击中的射线
+	boxT, inBox := torus.AABB.Clip(&ray, rayT)
+	if !inBox {
+		return false, hitRecord
+	}
+	entry := boxT.Min
+
+	length := ray.Direction.Length()
+	d := ray.Direction.Div(length)
+	o := Vec3(ray.At(entry)).Sub(Vec3(torus.Center))
+	R2 := torus.MajorRadius * torus.MajorRadius
+	r2 := torus.MinorRadius * torus.MinorRadius
+
+	// 单位方向下 t⁴ + c3 t³ + c2 t² + c1 t + c0 = 0，t为从入口开始的距离
+	e := o.LengthSquared() - R2 - r2
+	f := o.Dot(d)
+	coefficients := [5]float64{
+		e*e - 4*R2*(r2-o.Y*o.Y),
+		4*f*e + 8*R2*o.Y*d.Y,
+		2*e + 4*f*f + 4*R2*d.Y*d.Y,
+		4 * f,
+		1,
+	}
+	for _, s := range solveQuartic(coefficients) {
+		t := entry + s/length
+		if !rayT.Surrounds(t) {
+			continue
+		}
+		hitRecord.Time = t
+		hit = true
+		break
+	}
+	if !hit {
+		return false, hitRecord
+	}
+	hitRecord.HitPoint = ray.At(hitRecord.Time)
+	p := Vec3(hitRecord.HitPoint).Sub(Vec3(torus.Center))
+	radial := math.Hypot(p.X, p.Z)
+	ringCenter := Vec3{}
+	if radial > 0 {
+		ringCenter = Vec3{X: p.X / radial * torus.MajorRadius, Z: p.Z / radial * torus.MajorRadius}
+	}
+	hitRecord.setFaceNormal(ray, p.Sub(ringCenter).Normalize())
+	hitRecord.U = angleUV(-p.Z, p.X)
+	hitRecord.V = angleUV(p.Y, radial-torus.MajorRadius)
+	hitRecord.Material = torus.Material
+	return true, hitRecord
+}
+
+/*
+多项式求根（Graphics Gems I, Jochen Schwarze）：
+二次方程用求根公式，三次方程用卡尔达诺公式/三角解法，四次方程用费拉里方法化为一个三次预解式和两个二次方程。
+coefficients[i] 为 t^i 的系数，返回从小到大的实根。
+*/
+
+const polynomialEpsilon = 1e-9
+
+func isZero(x float64) bool {
+	return math.Abs(x) < polynomialEpsilon
+}
+
+func solveQuadric(c [3]float64) []float64 {
+	p := c[1] / (2 * c[2])
+	q := c[0] / c[2]
+	discriminant := p*p - q
+	switch {
+	case isZero(discriminant):
+		return []float64{-p}
+	case discriminant < 0:
+		return nil
+	}
+	sqrtD := math.Sqrt(discriminant)
+	return []float64{sqrtD - p, -sqrtD - p}
+}
+
+func solveCubic(c [4]float64) []float64 {
+	// 化为 x³ + Ax² + Bx + C = 0，再用 x = y - A/3 消去二次项：y³ + 3py + 2q = 0
+	a := c[2] / c[3]
+	b := c[1] / c[3]
+	cc := c[0] / c[3]
+	sqA := a * a
+	p := (-sqA/3 + b) / 3
+	q := (2.0/27*a*sqA - a*b/3 + cc) / 2
+	cbP := p * p * p
+	discriminant := q*q + cbP
+
+	var roots []float64
+	switch {
+	case isZero(discriminant):
+		if isZero(q) {
+			roots = []float64{0}
+		} else {
+			u := math.Cbrt(-q)
+			roots = []float64{2 * u, -u}
+		}
+	case discriminant < 0:
+		// 三个不同实根
+		phi := math.Acos(-q/math.Sqrt(-cbP)) / 3
+		t := 2 * math.Sqrt(-p)
+		roots = []float64{t * math.Cos(phi), -t * math.Cos(phi+math.Pi/3), -t * math.Cos(phi-math.Pi/3)}
+	default:
+		sqrtD := math.Sqrt(discriminant)
+		roots = []float64{math.Cbrt(sqrtD-q) - math.Cbrt(sqrtD+q)}
+	}
+	for i := range roots {
+		roots[i] -= a / 3
+	}
+	return roots
+}
+
+func solveQuartic(c [5]float64) []float64 {
+	// 化为 x⁴ + Ax³ + Bx² + Cx + D = 0，再用 x = y - A/4 消去三次项：y⁴ + py² + qy + r = 0
+	a := c[3] / c[4]
+	b := c[2] / c[4]
+	cc := c[1] / c[4]
+	d := c[0] / c[4]
+	sqA := a * a
+	p := -3.0/8*sqA + b
+	q := sqA*a/8 - a*b/2 + cc
+	r := -3.0/256*sqA*sqA + sqA*b/16 - a*cc/4 + d
+
+	var roots []float64
+	if isZero(r) {
+		// y(y³ + py + q) = 0
+		roots = append(solveCubic([4]float64{q, p, 0, 1}), 0)
+	} else {
+		// 预解式的一个实根
+		z := solveCubic([4]float64{r*p/2 - q*q/8, -r, -p / 2, 1})[0]
+		u := z*z - r
+		v := 2*z - p
+		switch {
+		case isZero(u):
+			u = 0
+		case u > 0:
+			u = math.Sqrt(u)
+		default:
+			return nil
+		}
+		switch {
+		case isZero(v):
+			v = 0
+		case v > 0:
+			v = math.Sqrt(v)
+		default:
+			return nil
+		}
+		if q < 0 {
+			v = -v
+		}
+		roots = append(solveQuadric([3]float64{z - u, v, 1}), solveQuadric([3]float64{z + u, -v, 1})...)
+	}
+	for i := range roots {
+		x := roots[i] - a/4
+		// 牛顿迭代修正精度
+		for iteration := 0; iteration < 2; iteration++ {
+			f := (((c[4]*x+c[3])*x+c[2])*x+c[1])*x + c[0]
+			df := ((4*c[4]*x+3*c[3])*x+2*c[2])*x + c[1]
+			if df == 0 {
+				break
+			}
+			x -= f / df
+		}
+		roots[i] = x
+	}
+	sort.Float64s(roots)
+	return roots
+}
@@ -0,0 +1,119 @@
+package core
+
+// Transform 仿射变换 p' = Translation + Rotation*(Scale∘p)，Scale各分量不能为0
+type Transform struct {
+	Translation Vec3
+	Rotation    Mat3
+	Scale       Vec3
+}
+
+// NewTransform rotation为欧拉角（度）
+func NewTransform(translation, rotation, scale Vec3) Transform {
+	return Transform{
+		Translation: translation,
+		Rotation:    RotationMat3(rotation),
+		Scale:       scale,
+	}
+}
+
+// Vector 变换方向向量（不含平移）
+func (t Transform) Vector(v Vec3) Vec3 {
+	return t.Rotation.MulVec3(v.MultiplicationVec3(t.Scale))
+}
+
+// Point 变换点
+func (t Transform) Point(p Point) Point {
+	return Point(t.Vector(Vec3(p)).Add(t.Translation))
+}
+
+// InverseVector 逆变换方向向量，不做归一化
+func (t Transform) InverseVector(v Vec3) Vec3 {
+	r := t.Rotation.Transpose().MulVec3(v)
+	return Vec3{r.X / t.Scale.X, r.Y / t.Scale.Y, r.Z / t.Scale.Z}
+}
+
+// InversePoint 逆变换点
+func (t Transform) InversePoint(p Point) Point {
+	return Point(t.InverseVector(Vec3(p).Sub(t.Translation)))
+}
+
+// Normal 变换法线：使用逆转置矩阵 (R*S)^-T = R*S^-1，结果为单位向量
+func (t Transform) Normal(n Vec3) Vec3 {
+	return t.Rotation.MulVec3(Vec3{n.X / t.Scale.X, n.Y / t.Scale.Y, n.Z / t.Scale.Z}).Normalize()
+}
+
+// Box 变换包围盒的8个顶点后重新求包围盒，无界包围盒变换后仍为无界
+func (t Transform) Box(aabb *AABB) *AABB {
+	if !aabb.IsBounded() {
+		return NewAABB(NewUniverseInterval(), NewUniverseInterval(), NewUniverseInterval())
+	}
+	result := NewAABB(NewEmptyInterval(), NewEmptyInterval(), NewEmptyInterval())
+	for _, x := range []float64{aabb.X.Min, aabb.X.Max} {
+		for _, y := range []float64{aabb.Y.Min, aabb.Y.Max} {
+			for _, z := range []float64{aabb.Z.Min, aabb.Z.Max} {
+				p := t.Point(Point{x, y, z})
+				result = NewAABBFromAABB(result, NewAABBFromPoints(p, p))
+			}
+		}
+	}
+	return result
+}
+
+// transformHit 在物体局部空间中求交并把结果变换回世界空间，
+// 局部射线方向不归一化，因此两个空间中的射线参数t相同
+func transformHit(item HittableItemI, t Transform, ray Ray, rayT Interval) (bool, HitRecord) {
+	localRay := Ray{
+		Origin:    t.InversePoint(ray.Origin),
+		Direction: t.InverseVector(ray.Direction),
+		TM:        ray.TM,
+		counters:  ray.counters,
+		rng:       ray.rng,
+	}
+	hit, hitRecord := item.Hittable(localRay, rayT)
+	if !hit {
+		return false, hitRecord
+	}
+	hitRecord.HitPoint = ray.At(hitRecord.Time)
+	// 局部空间中已按射线方向确定法线朝向，线性变换不改变法线与射线的朝向关系
+	hitRecord.Normal = t.Normal(hitRecord.Normal)
+	return true, hitRecord
+}
+
+// Instance 对物体施加静态变换的实例，可以让同一个物体以不同的位置、朝向和大小多次出现在场景中
+type Instance struct {
+	Item      HittableItemI
+	Transform Transform
+	AABB      *AABB
+}
+
+func NewInstance(item HittableItemI, transform Transform) *Instance {
+	return &Instance{
+		Item:      item,
+		Transform: transform,
+		AABB:      transform.Box(item.GetBoundingBox()),
+	}
+}
+
+func (ins *Instance) Hittable(ray Ray, rayT Interval) (hit bool, hitRecord HitRecord) {
+	return transformHit(ins.Item, ins.Transform, ray, rayT)
+}
+
+func (ins *Instance) GetMaterial() MaterialI {
+	if holder, ok := ins.Item.(MaterialHolderI); ok {
+		return holder.GetMaterial()
+	}
+	return nil
+}
+
+func (ins *Instance) Children() []HittableItemI {
+	return []HittableItemI{ins.Item}
+}
+
+// SetBoundingBox 实例的包围盒始终由物体包围盒变换得到，传入值被忽略
+func (ins *Instance) SetBoundingBox(aabb *AABB) {
+	ins.AABB = ins.Transform.Box(ins.Item.GetBoundingBox())
+}
+
+func (ins *Instance) GetBoundingBox() *AABB {
+	return ins.AABB
+}
@@ -4,6 +4,7 @@ package core
 import (
 	"RayTracingInOneWeekend/utils"
 	"math"
+	"math/rand"
 )
 
 type Vec3 struct {
@@ -96,9 +97,10 @@ func RandomBetween(min, max float64) Vec3 {
 	return Vec3{utils.RandomBetween(min, max), utils.RandomBetween(min, max), utils.RandomBetween(min, max)}
 }
 
-func RandomNormalizedVec3() Vec3 {
+// RandomNormalizedVec3 单位球面上均匀分布的随机方向，rng为nil时使用全局随机源
+func RandomNormalizedVec3(rng *rand.Rand) Vec3 {
 	for {
-		var p = RandomBetween(-1.0, 1.0)
+		var p = Vec3{utils.RandomBetweenFrom(rng, -1, 1), utils.RandomBetweenFrom(rng, -1, 1), utils.RandomBetweenFrom(rng, -1, 1)}
 		var l = p.Length()
 		if l >= 1e-160 && l <= 1.0 {
 			return p.Div(l)
@@ -107,8 +109,8 @@ func RandomNormalizedVec3() Vec3 {
 }
 
 // RandomOnHemisphere 通过计算表面法向量和随机向量的点积来判断它是否位于正确的半球。如果点积为正，则向量位于正确的半球。如果点积为负，则需要反转向量。
-func RandomOnHemisphere(normal Vec3) Vec3 {
-	onNormalizedVec3 := RandomNormalizedVec3()
+func RandomOnHemisphere(normal Vec3, rng *rand.Rand) Vec3 {
+	onNormalizedVec3 := RandomNormalizedVec3(rng)
 	if onNormalizedVec3.Dot(normal) < 0 {
 		return onNormalizedVec3.MultiplicationNum(-1.0)
 	} else {
@@ -135,9 +137,9 @@ func (v Vec3) Refract(n Vec3, etaiOverEtat float64) Vec3 {
 }
 
 // 在单位盘内生成随机点,长度小于1的结果保留
-func RandomInUnitDisk() Vec3 {
+func RandomInUnitDisk(rng *rand.Rand) Vec3 {
 	for {
-		p := Vec3{utils.RandomBetween(-1, 1), utils.RandomBetween(-1, 1), 0}
+		p := Vec3{utils.RandomBetweenFrom(rng, -1, 1), utils.RandomBetweenFrom(rng, -1, 1), 0}
 		if p.LengthSquared() < 1 {
 			return p
 		}
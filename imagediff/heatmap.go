@@ -0,0 +1,66 @@
+package imagediff
+
+import "math"
+
+/*
+误差热力图：
+与FLIP的可视化方式相同，每个像素的误差映射到magma色表，黑色为无误差，亮黄色为最大误差。
+像素误差取三个通道差值的均方根，除以scale后截断到[0, 1]；scale<=0时使用图中的最大误差，
+固定scale便于比较多组结果，自动scale便于看清很小的差异。
+*/
+
+// magma 色表的控制点，等间距分布在[0, 1]上
+var magma = [][3]float64{
+	{0.001, 0.000, 0.014},
+	{0.114, 0.065, 0.277},
+	{0.317, 0.072, 0.485},
+	{0.512, 0.128, 0.507},
+	{0.716, 0.215, 0.475},
+	{0.904, 0.305, 0.388},
+	{0.987, 0.536, 0.382},
+	{0.996, 0.769, 0.534},
+	{0.987, 0.991, 0.750},
+}
+
+// Heatmap 生成a与b的误差热力图，返回图像与使用的scale
+func Heatmap(a, b *Image, scale float64) (*Image, float64, error) {
+	if err := sameSize(a, b); err != nil {
+		return nil, 0, err
+	}
+	pixelErrors := make([]float64, a.Width*a.Height)
+	maxError := 0.0
+	for i := range pixelErrors {
+		sum := 0.0
+		for c := 0; c < 3; c++ {
+			d := a.Pix[3*i+c] - b.Pix[3*i+c]
+			sum += d * d
+		}
+		pixelErrors[i] = math.Sqrt(sum / 3)
+		maxError = math.Max(maxError, pixelErrors[i])
+	}
+	if scale <= 0 {
+		scale = maxError
+	}
+	heatmap := NewImage(a.Width, a.Height)
+	for i, e := range pixelErrors {
+		t := 0.0
+		if scale > 0 {
+			t = math.Min(e/scale, 1)
+		}
+		rgb := colormap(t)
+		copy(heatmap.Pix[3*i:3*i+3], rgb[:])
+	}
+	return heatmap, scale, nil
+}
+
+// colormap 在magma控制点之间线性插值，t∈[0, 1]
+func colormap(t float64) [3]float64 {
+	x := t * float64(len(magma)-1)
+	k := min(int(x), len(magma)-2)
+	f := x - float64(k)
+	var rgb [3]float64
+	for c := range rgb {
+		rgb[c] = magma[k][c]*(1-f) + magma[k+1][c]*f
+	}
+	return rgb
+}
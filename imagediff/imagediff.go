@@ -0,0 +1,255 @@
+// Package imagediff 比较两张渲染结果，计算 MSE/RMSE/PSNR/SSIM 并生成差异图和误差热力图
+package imagediff
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Image 按行存储的RGB浮点图像，通道值通常在[0, 1]
+type Image struct {
+	Width, Height int
+	Pix           []float64 // RGB交错，长度为Width*Height*3
+}
+
+func NewImage(width, height int) *Image {
+	return &Image{
+		Width:  width,
+		Height: height,
+		Pix:    make([]float64, width*height*3),
+	}
+}
+
+// FromPixels 将8位像素转换为[0, 1]的浮点图像
+func FromPixels(width, height int, pixels []utils.Pixel) (*Image, error) {
+	if len(pixels) != width*height {
+		return nil, fmt.Errorf("got %d pixels for a %dx%d image", len(pixels), width, height)
+	}
+	img := NewImage(width, height)
+	for i, p := range pixels {
+		img.Pix[3*i] = float64(p.R) / 255
+		img.Pix[3*i+1] = float64(p.G) / 255
+		img.Pix[3*i+2] = float64(p.B) / 255
+	}
+	return img, nil
+}
+
+// FromImage 将标准库图像转换为[0, 1]的浮点图像，忽略透明度
+func FromImage(src image.Image) *Image {
+	bounds := src.Bounds()
+	img := NewImage(bounds.Dx(), bounds.Dy())
+	for j := 0; j < img.Height; j++ {
+		for i := 0; i < img.Width; i++ {
+			r, g, b, _ := src.At(bounds.Min.X+i, bounds.Min.Y+j).RGBA()
+			k := 3 * (j*img.Width + i)
+			img.Pix[k] = float64(r) / 0xffff
+			img.Pix[k+1] = float64(g) / 0xffff
+			img.Pix[k+2] = float64(b) / 0xffff
+		}
+	}
+	return img
+}
+
+// Load 按扩展名读取PFM图片，其他格式交给image.Decode（PPM、PNG或JPEG）。
+// PFM中是线性的HDR值，其他格式是伽马校正后的[0, 1]值，两者不能直接比较
+func Load(path string) (*Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	defer f.Close()
+	var img *Image
+	if IsLinear(path) {
+		img, err = DecodePFM(f)
+	} else {
+		// PPM解码器由utils注册
+		var src image.Image
+		if src, _, err = image.Decode(f); err == nil {
+			img = FromImage(src)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// IsLinear path是否是保存线性HDR值的格式（PFM）
+func IsLinear(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".pfm")
+}
+
+// Pixels 转换为8位像素，超出[0, 1]的值被截断
+func (img *Image) Pixels() []utils.Pixel {
+	pixels := make([]utils.Pixel, img.Width*img.Height)
+	for i := range pixels {
+		pixels[i] = utils.Pixel{
+			R: to8Bit(img.Pix[3*i]),
+			G: to8Bit(img.Pix[3*i+1]),
+			B: to8Bit(img.Pix[3*i+2]),
+		}
+	}
+	return pixels
+}
+
+// Save 按扩展名保存，.pfm保存原始浮点值，其他格式见 utils.SaveImage
+func (img *Image) Save(path string) (err error) {
+	if !IsLinear(path) {
+		return utils.SaveImage(path, img.Width, img.Height, img.Pixels())
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("save %s: %w", path, closeErr)
+		}
+	}()
+	if err := EncodePFM(f, img); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	return nil
+}
+
+func to8Bit(x float64) int {
+	return int(math.Round(math.Max(0, math.Min(1, x)) * 255))
+}
+
+// luminance 每个像素的亮度（Rec.709系数）
+func (img *Image) luminance() []float64 {
+	y := make([]float64, img.Width*img.Height)
+	for i := range y {
+		y[i] = 0.2126*img.Pix[3*i] + 0.7152*img.Pix[3*i+1] + 0.0722*img.Pix[3*i+2]
+	}
+	return y
+}
+
+// Metrics 两张图片的差异指标
+type Metrics struct {
+	MSE  float64 // 所有通道的均方误差
+	RMSE float64 // 均方根误差
+	PSNR float64 // 峰值信噪比(dB)，峰值为1，图片完全相同时为+Inf
+	SSIM float64 // 亮度上的平均结构相似度，1为完全相同
+}
+
+func (m Metrics) String() string {
+	return fmt.Sprintf("MSE %.6g, RMSE %.6g, PSNR %.2f dB, SSIM %.4f", m.MSE, m.RMSE, m.PSNR, m.SSIM)
+}
+
+// Compare 计算a与b的差异指标，两张图片尺寸必须相同
+func Compare(a, b *Image) (Metrics, error) {
+	if err := sameSize(a, b); err != nil {
+		return Metrics{}, err
+	}
+	var m Metrics
+	m.MSE = mse(a, b)
+	m.RMSE = math.Sqrt(m.MSE)
+	m.PSNR = psnr(m.MSE)
+	m.SSIM = ssim(a.luminance(), b.luminance(), a.Width, a.Height)
+	return m, nil
+}
+
+// Diff 逐通道的差的绝对值乘以gain，gain>1便于看清细小差异
+func Diff(a, b *Image, gain float64) (*Image, error) {
+	if err := sameSize(a, b); err != nil {
+		return nil, err
+	}
+	diff := NewImage(a.Width, a.Height)
+	for i := range diff.Pix {
+		diff.Pix[i] = math.Abs(a.Pix[i]-b.Pix[i]) * gain
+	}
+	return diff, nil
+}
+
+func sameSize(a, b *Image) error {
+	if a.Width != b.Width || a.Height != b.Height {
+		return fmt.Errorf("image sizes differ: %dx%d and %dx%d", a.Width, a.Height, b.Width, b.Height)
+	}
+	return nil
+}
+
+func mse(a, b *Image) float64 {
+	if len(a.Pix) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for i := range a.Pix {
+		d := a.Pix[i] - b.Pix[i]
+		sum += d * d
+	}
+	return sum / float64(len(a.Pix))
+}
+
+func psnr(mse float64) float64 {
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(1/mse)
+}
+
+/*
+SSIM：
+对每个像素取 11x11、σ=1.5 的高斯窗口计算局部均值、方差与协方差，
+SSIM = (2μaμb+C1)(2σab+C2) / ((μa²+μb²+C1)(σa²+σb²+C2))，C1=(0.01L)²，C2=(0.03L)²，L=1，
+最后对所有像素取平均。窗口超出图像的部分不参与计算，权重在图像内重新归一化。
+*/
+
+const (
+	ssimRadius = 5
+	ssimSigma  = 1.5
+	ssimC1     = 0.01 * 0.01
+	ssimC2     = 0.03 * 0.03
+)
+
+func ssim(a, b []float64, width, height int) float64 {
+	if len(a) == 0 {
+		return 1
+	}
+	var weights [2*ssimRadius + 1]float64
+	for k := range weights {
+		d := float64(k - ssimRadius)
+		weights[k] = math.Exp(-d * d / (2 * ssimSigma * ssimSigma))
+	}
+	total := 0.0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sumW, muA, muB, aa, bb, ab float64
+			for dy := -ssimRadius; dy <= ssimRadius; dy++ {
+				j := y + dy
+				if j < 0 || j >= height {
+					continue
+				}
+				for dx := -ssimRadius; dx <= ssimRadius; dx++ {
+					i := x + dx
+					if i < 0 || i >= width {
+						continue
+					}
+					w := weights[dy+ssimRadius] * weights[dx+ssimRadius]
+					va, vb := a[j*width+i], b[j*width+i]
+					sumW += w
+					muA += w * va
+					muB += w * vb
+					aa += w * va * va
+					bb += w * vb * vb
+					ab += w * va * vb
+				}
+			}
+			muA /= sumW
+			muB /= sumW
+			varA := aa/sumW - muA*muA
+			varB := bb/sumW - muB*muB
+			cov := ab/sumW - muA*muB
+			total += (2*muA*muB + ssimC1) * (2*cov + ssimC2) /
+				((muA*muA + muB*muB + ssimC1) * (varA + varB + ssimC2))
+		}
+	}
+	return total / float64(width*height)
+}
@@ -0,0 +1,176 @@
+package imagediff
+
+import (
+	"RayTracingInOneWeekend/utils"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func gradient(width, height int) *Image {
+	img := NewImage(width, height)
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			k := 3 * (j*width + i)
+			img.Pix[k] = float64(i) / float64(width)
+			img.Pix[k+1] = float64(j) / float64(height)
+			img.Pix[k+2] = 0.5
+		}
+	}
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	a := gradient(32, 16)
+	m, err := Compare(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MSE != 0 || !math.IsInf(m.PSNR, 1) || math.Abs(m.SSIM-1) > 1e-9 {
+		t.Errorf("identical images: %v", m)
+	}
+
+	// 整体偏移0.1：MSE=0.01，PSNR=20dB，结构不变SSIM接近1
+	b := gradient(32, 16)
+	for i := range b.Pix {
+		b.Pix[i] += 0.1
+	}
+	m, _ = Compare(a, b)
+	if math.Abs(m.MSE-0.01) > 1e-9 || math.Abs(m.RMSE-0.1) > 1e-9 || math.Abs(m.PSNR-20) > 1e-6 || m.SSIM < 0.95 {
+		t.Errorf("offset images: %v", m)
+	}
+
+	// 加入逐像素噪声后结构相似度明显下降
+	c := gradient(32, 16)
+	for i := range c.Pix {
+		c.Pix[i] += 0.2 * float64((i/3)%2*2-1)
+	}
+	if m, _ := Compare(a, c); m.SSIM > 0.5 {
+		t.Errorf("noisy image: %v", m)
+	}
+
+	if _, err := Compare(a, gradient(16, 16)); err == nil {
+		t.Error("expected a size mismatch error")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	a := gradient(8, 4)
+	path := filepath.Join(t.TempDir(), "a.png")
+	if err := a.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 8位量化误差不超过半个色阶
+	if m, _ := Compare(a, b); m.RMSE > 0.5/255 {
+		t.Errorf("round trip: %v", m)
+	}
+	pixels, _ := FromPixels(1, 1, []utils.Pixel{{R: 255, G: 0, B: 51}})
+	if pixels.Pix[0] != 1 || pixels.Pix[1] != 0 || pixels.Pix[2] != 0.2 {
+		t.Errorf("FromPixels = %v", pixels.Pix)
+	}
+	diff, _ := Diff(a, b, 4)
+	for _, v := range diff.Pix {
+		if v > 2.0/255 {
+			t.Errorf("diff %v", v)
+			break
+		}
+	}
+}
+
+func TestPFM(t *testing.T) {
+	a := gradient(5, 3)
+	a.Pix[0] = 12.5 // HDR值原样保存
+	path := filepath.Join(t.TempDir(), "a.pfm")
+	if err := a.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := Compare(a, b); m.RMSE > 1e-6 {
+		t.Errorf("round trip: %v", m)
+	}
+
+	// 大端序灰度图，第一行数据是图像最下面一行
+	gray := "Pf\n1 2\n1.0\n\x3f\x80\x00\x00\x00\x00\x00\x00"
+	img, err := DecodePFM(strings.NewReader(gray))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Pix[0] != 0 || img.Pix[3] != 1 || img.Pix[4] != 1 || img.Pix[5] != 1 {
+		t.Errorf("grayscale = %v", img.Pix)
+	}
+	for _, data := range []string{"P6\n1 1\n-1\n", "PF\n1\n-1\n", "PF\n1 1\n0\n", "PF\n1 1\n-1\n\x00\x00"} {
+		if _, err := DecodePFM(strings.NewReader(data)); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+// 头部声明32768x32768但只有一行数据的文件，不能按头部尺寸预先分配约24GiB的像素
+func TestDecodePFMTruncatedHugeHeader(t *testing.T) {
+	data := append([]byte("PF\n32768 32768\n-1.0\n"), make([]byte, 12*32768)...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := DecodePFM(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "read row 1") {
+		t.Errorf("err = %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("allocated %d MiB for a truncated file", allocated>>20)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{"a.png", "a.ppm"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := utils.SaveImage(path, 2, 1, []utils.Pixel{{R: 255}, {B: 51}}); err != nil {
+			t.Fatal(err)
+		}
+		img, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Width != 2 || img.Pix[0] != 1 || img.Pix[5] != 0.2 {
+			t.Errorf("%s: loaded %dx%d %v", name, img.Width, img.Height, img.Pix)
+		}
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	a := gradient(4, 1)
+	b := gradient(4, 1)
+	b.Pix[3*3] += 0.3 // 只有最后一个像素有误差
+	heatmap, scale, err := Heatmap(a, b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(scale-0.3/math.Sqrt(3)) > 1e-9 {
+		t.Errorf("scale = %v", scale)
+	}
+	if heatmap.Pix[0] != magma[0][0] || heatmap.Pix[9] != magma[len(magma)-1][0] {
+		t.Errorf("heatmap = %v", heatmap.Pix)
+	}
+	// 固定scale时误差超过scale的部分截断到色表顶端
+	heatmap, _, _ = Heatmap(a, b, 0.01)
+	if heatmap.Pix[9] != magma[len(magma)-1][0] {
+		t.Errorf("clamped heatmap = %v", heatmap.Pix)
+	}
+	if _, _, err := Heatmap(a, gradient(2, 2), 0); err == nil {
+		t.Error("expected a size mismatch error")
+	}
+}
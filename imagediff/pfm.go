@@ -0,0 +1,97 @@
+package imagediff
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+PFM（Portable Float Map）：
+文本头 "PF"（RGB）或 "Pf"（灰度），换行后是宽高，再换行后是比例因子，负数表示小端序，
+随后是按行存储的32位浮点数，第一行是图像最下面一行。值为线性的HDR数据，不做伽马校正。
+*/
+
+// DecodePFM 读取PFM图片，灰度图的三个通道相同
+func DecodePFM(r io.Reader) (*Image, error) {
+	br := bufio.NewReader(r)
+	var lines [3]string // 格式、宽高、比例因子
+	for i := range lines {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("pfm: read header: %w", unexpectedEOF(err))
+		}
+		lines[i] = strings.TrimSpace(line)
+	}
+	channels := 0
+	switch lines[0] {
+	case "PF":
+		channels = 3
+	case "Pf":
+		channels = 1
+	default:
+		return nil, fmt.Errorf("pfm: unsupported magic number %q, expected PF or Pf", lines[0])
+	}
+	var width, height int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &width, &height); err != nil || width <= 0 || height <= 0 || width > 1<<15 || height > 1<<15 {
+		return nil, fmt.Errorf("pfm: invalid size %q", lines[1])
+	}
+	scale, err := strconv.ParseFloat(lines[2], 64)
+	if err != nil || scale == 0 {
+		return nil, fmt.Errorf("pfm: invalid scale %q", lines[2])
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+
+	// 逐行读取后追加，头部声明的尺寸再大，分配的内存也不会超过实际读到的数据；
+	// 文件中的行自下而上，读完后再上下翻转
+	img := &Image{Width: width, Height: height}
+	row := make([]byte, 4*channels*width)
+	pixels := make([]float64, 3*width)
+	for y := 0; y < height; y++ {
+		if _, err := io.ReadFull(br, row); err != nil {
+			return nil, fmt.Errorf("pfm: read row %d: %w", y, unexpectedEOF(err))
+		}
+		for i := 0; i < width; i++ {
+			for c := 0; c < 3; c++ {
+				offset := 4 * (i*channels + min(c, channels-1))
+				pixels[3*i+c] = float64(math.Float32frombits(order.Uint32(row[offset:])))
+			}
+		}
+		img.Pix = append(img.Pix, pixels...)
+	}
+	stride := 3 * width
+	for top, bottom := 0, height-1; top < bottom; top, bottom = top+1, bottom-1 {
+		copy(pixels, img.Pix[top*stride:(top+1)*stride])
+		copy(img.Pix[top*stride:], img.Pix[bottom*stride:(bottom+1)*stride])
+		copy(img.Pix[bottom*stride:], pixels)
+	}
+	return img, nil
+}
+
+// EncodePFM 以小端序的RGB格式写出PFM
+func EncodePFM(w io.Writer, img *Image) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", img.Width, img.Height)
+	var buf [4]byte
+	for j := img.Height - 1; j >= 0; j-- {
+		for _, v := range img.Pix[3*j*img.Width : 3*(j+1)*img.Width] {
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(v)))
+			bw.Write(buf[:])
+		}
+	}
+	return bw.Flush()
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
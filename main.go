@@ -1,71 +1,194 @@
+// 渲染器命令行：加载JSON场景文件或按名称选择预设场景，命令行参数覆盖场景中的渲染设置
+//
+//	go run . -scene scene.json -width 800 -spp 64 -threads 8 -o out.png
+//	go run . -preset cornell -spp 64 -o cornell.png
+//	go run . -list
 package main
 
 import (
 	"RayTracingInOneWeekend/core"
+	"RayTracingInOneWeekend/scenes"
 	"RayTracingInOneWeekend/utils"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 )
 
+type options struct {
+	scene    string
+	preset   string
+	list     bool
+	width    int
+	height   int
+	spp      int
+	depth    int
+	seed     int64
+	threads  int
+	output   string
+	bvh      bool
+	progress string
+	set      map[string]bool // 命令行中显式指定的参数
+}
+
+func parseOptions(args []string) (*options, error) {
+	opts := &options{}
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.StringVar(&opts.scene, "scene", "", "JSON scene file")
+	flags.StringVar(&opts.preset, "preset", "weekend", "built-in scene used when no -scene is given: "+strings.Join(scenes.Names(), ", "))
+	flags.BoolVar(&opts.list, "list", false, "list the built-in scenes and exit")
+	flags.StringVar(&scenes.AssetDir, "assets", scenes.AssetDir, "directory with the image textures used by the built-in scenes")
+	flags.IntVar(&opts.width, "width", 0, "image width (keeps the aspect ratio unless -height is also given)")
+	flags.IntVar(&opts.height, "height", 0, "image height (keeps the aspect ratio unless -width is also given)")
+	flags.IntVar(&opts.spp, "spp", 0, "samples per pixel")
+	flags.IntVar(&opts.depth, "depth", 0, "max ray depth")
+	flags.Int64Var(&opts.seed, "seed", 0, "random seed for scene layout and sampling, renders are reproducible for any number of workers (default: taken from the clock)")
+	flags.IntVar(&opts.threads, "threads", 0, "render threads, 1 renders single-threaded (default: scene setting or number of CPUs)")
+	flags.StringVar(&opts.output, "o", "", "output path, the format is chosen by extension: "+fmt.Sprint(utils.ImageFormats)+" (default: scene setting or o.ppm)")
+	flags.BoolVar(&opts.bvh, "bvh", false, "use a BVH for ray intersection (default: scene setting)")
+	flags.StringVar(&opts.progress, "progress", "bar", "progress output on stderr: bar, json (one JSON object per line) or none")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+	if _, err := newProgressReporter(opts.progress); err != nil {
+		return nil, err
+	}
+	opts.set = map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		opts.set[f.Name] = true
+	})
+	if opts.set["scene"] && opts.set["preset"] {
+		return nil, fmt.Errorf("-scene and -preset cannot be used together")
+	}
+	for name, value := range map[string]int{"width": opts.width, "height": opts.height, "spp": opts.spp, "depth": opts.depth, "threads": opts.threads} {
+		if opts.set[name] && value <= 0 {
+			return nil, fmt.Errorf("-%s must be positive, got %d", name, value)
+		}
+	}
+	return opts, nil
+}
+
 func main() {
-	// 材质
-	// 网格纹理
-	checkerTexture := core.NewCheckerTexture(0.32, core.Color{X: .2, Y: .3, Z: .1}, core.Color{X: .9, Y: .9, Z: .9})
-	var groundMaterial = core.LambertianReflectionMaterial{Albedo: core.Color{X: 0.5, Y: 0.5, Z: 0.5}, Tex: checkerTexture}
-	groundSphere := core.NewSphere(core.Point{Y: -1000}, 1000).WithMaterial(groundMaterial)
-	s2 := core.NewSphere(core.Point{X: 0.0, Y: 0.0, Z: -1.0}, 0.5).WithMaterial(core.LambertianReflectionMaterial{Albedo: core.Color{X: 0.1, Y: 0.2, Z: 0.5}})
-	s3 := core.NewSphere(core.Point{X: -1.0, Z: -1.0}, 0.5).WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5})
-	s4 := core.NewSphere(core.Point{X: 1.0, Z: -1.0}, 0.5).WithMaterial(core.MetalMaterial{Albedo: core.Color{X: 0.8, Y: 0.6, Z: 0.2}, Fuzz: 1.0})
-	// 相机构建
-	camera := core.NewCamera(core.Point{}, core.Point{X: 13, Y: 2, Z: 3}, 16.0/9.0, 20, 400, 512, 10, true, 0.6, 10.0)
-	camera.Add(groundSphere, s2, s3, s4)
-	// 随机构建场景
-	for i := -8; i < 8; i++ {
-		for j := -8; j < 8; j++ {
-			chooseSize := utils.Random()
-			R := 0.0
-			switch {
-			case chooseSize < 0.8:
-				R = utils.RandomBetween(0.1, 0.25)
-			case chooseSize < 0.95:
-				R = utils.RandomBetween(0.25, 0.4)
-			default:
-				R = utils.RandomBetween(0.4, 0.6)
+	opts, err := parseOptions(os.Args[1:])
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "render:", err)
+		os.Exit(2)
+	}
+	if opts.list {
+		for _, preset := range scenes.Presets() {
+			fmt.Printf("%-10s %s\n", preset.Name, preset.Description)
+		}
+		return
+	}
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "render:", err)
+		os.Exit(1)
+	}
+}
 
-			}
-			chooseMat := utils.Random()
-			center := core.Point{X: float64(i) + 0.9*utils.Random(), Y: R, Z: float64(j) + 0.9*utils.Random()}
-			if core.Vec3(center).Sub(core.Vec3{X: 4.0, Y: 2.0}).Length() > 0.9 {
-				var material core.MaterialI
-				albedo := core.Color(core.Random())
-				//
-				switch {
-				case chooseMat < 0.8:
-					// diffuse
-					material = core.LambertianReflectionMaterial{Albedo: albedo}
-				case chooseMat < 0.90:
-					// metal
-					fuzz := utils.Random()
-					material = core.MetalMaterial{
-						Albedo: albedo,
-						Fuzz:   fuzz,
-					}
-				default:
-					// glass
-					material = core.DielectricMaterial{
-						RefractionIndex: 1.5,
-					}
-				}
-				sphere := core.NewSphere(center, R).WithMaterial(material)
-				if utils.Random() <= 0.3 {
-					sphere.SetUniformLinearMovement(core.Point{Y: utils.RandomBetween(0, 0.3)})
-				}
-				camera.Add(sphere)
-			}
+func run(opts *options) error {
+	seed := opts.seed
+	if !opts.set["seed"] {
+		seed = time.Now().UnixNano() // 未指定-seed时每次渲染的随机序列都不同
+	}
+	utils.Seed(seed)
+	loadStart := time.Now()
+	var camera *core.Camera
+	var settings core.RenderSettings
+	if opts.scene != "" {
+		var err error
+		if camera, settings, err = core.LoadSceneFile(opts.scene); err != nil {
+			return err
 		}
+	} else {
+		var err error
+		if camera, err = scenes.Load(opts.preset, seed); err != nil {
+			return err
+		}
+		settings.Output = opts.preset
+	}
+
+	// 命令行参数覆盖场景设置
+	width, height := camera.ImageWidth, camera.ImageHeight
+	switch {
+	case opts.set["width"] && opts.set["height"]:
+		width, height = opts.width, opts.height
+	case opts.set["width"]:
+		width, height = opts.width, int(float64(opts.width)/camera.AspectRatio)
+	case opts.set["height"]:
+		width, height = int(float64(opts.height)*camera.AspectRatio), opts.height
+	}
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("image size %dx%d is empty", width, height)
+	}
+	if width != camera.ImageWidth || height != camera.ImageHeight {
+		camera.SetImageSize(width, height)
+	}
+	if opts.set["spp"] {
+		camera.SetSamplesPerPixel(opts.spp)
+	}
+	if opts.set["depth"] {
+		camera.MaxDepth = opts.depth
+	}
+	if opts.set["threads"] {
+		settings.Threads = opts.threads
+	}
+	if settings.Threads <= 0 {
+		settings.Threads = runtime.NumCPU()
+	}
+	if opts.set["o"] {
+		settings.Output = opts.output
+	}
+	if settings.Output == "" {
+		settings.Output = "o"
+	}
+	if filepath.Ext(settings.Output) == "" {
+		settings.Output += ".ppm"
+	}
+	// 渲染前检查输出格式，避免渲染完才发现无法保存
+	if _, err := utils.ImageFormat(settings.Output); err != nil {
+		return err
+	}
+	if opts.set["bvh"] {
+		camera.EnabledBVH(opts.bvh)
+	}
+	camera.Progress, _ = newProgressReporter(opts.progress)
+	loadTime := time.Since(loadStart)
+
+	var colors []core.Color
+	if settings.Threads > 1 {
+		colors = camera.MultithreadedRenderToColors(settings.Threads, 1000000)
+	} else {
+		colors = camera.RenderToColors()
+	}
+	if err := camera.SaveImage(settings.Output, colors); err != nil {
+		return err
+	}
+
+	fmt.Printf("image:            %dx%d, %d spp, max depth %d\n", camera.ImageWidth, camera.ImageHeight, camera.SamplesPerPixel, camera.MaxDepth)
+	fmt.Printf("scene:            %d objects, bvh %v, %d threads, loaded in %v\n", camera.Objects(), camera.BVHEnabled(), settings.Threads, loadTime.Round(time.Millisecond))
+	fmt.Println(camera.Stats())
+	fmt.Printf("output:           %s\n", settings.Output)
+	return nil
+}
+
+func newProgressReporter(name string) (utils.ProgressReporter, error) {
+	switch name {
+	case "bar":
+		return utils.NewProgressBar(os.Stderr, 50), nil
+	case "json":
+		return utils.NewJSONProgress(os.Stderr), nil
+	case "none":
+		return utils.SilentProgress{}, nil
 	}
-	//camera.Render("o")
-	camera.MultithreadedRender("mo", 8, 1000000)
-	//camera.EnabledBVH(true)
-	// BVH渲染，不一定更快
-	//camera.Render("o_bvh")
-	//camera.MultithreadedRender("mo_bvh", 6, 1000000)
+	return nil, fmt.Errorf("unknown -progress %q, expected bar, json or none", name)
 }
@@ -0,0 +1,20 @@
+package scenes
+
+import (
+	"RayTracingInOneWeekend/core"
+	"errors"
+	"io/fs"
+)
+
+// earthTexture 读取AssetDir中的earthmap.jpg，文件不存在时退回程序生成的地球纹理 core.GlobeTexture，
+// 这样没有资源文件的检出也能渲染 earth 和 nextweek 场景
+func earthTexture() (core.TextureI, error) {
+	texture, err := imageTexture("earthmap.jpg")
+	if errors.Is(err, fs.ErrNotExist) {
+		return core.NewGlobeTexture(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return texture, nil
+}
@@ -0,0 +1,116 @@
+package scenes
+
+import (
+	"RayTracingInOneWeekend/core"
+	"RayTracingInOneWeekend/imagediff"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*
+金图回归测试：
+用固定种子单线程渲染几个小尺寸预设场景，与 testdata/golden 下检入的参考图比较。
+同一平台上固定种子的渲染结果逐像素一致，不同平台浮点运算的细微差别会改变随机采样路径，
+因此按噪声水平设置 PSNR/SSIM 阈值，而不是要求完全相同。
+比较失败时把本次渲染和误差热力图写到临时目录，路径会打印在测试日志中。
+修改了渲染结果的提交需要重新生成参考图：
+
+	go test ./scenes -run TestGolden -update
+*/
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata/golden")
+
+type goldenCase struct {
+	preset        string
+	width, height int
+	spp           int
+	minPSNR       float64 // dB
+	minSSIM       float64
+}
+
+// 阈值取在不同随机序列的噪声（PSNR约30dB）与整体变暗10%（PSNR约24dB）之间。
+// 康奈尔盒光源很小，64spp下噪声远大于渲染错误带来的差异，不适合做金图测试
+var goldenCases = []goldenCase{
+	{preset: "weekend", width: 64, height: 36, spp: 64, minPSNR: 27, minSSIM: 0.9},
+	{preset: "checkered", width: 64, height: 36, spp: 64, minPSNR: 27, minSSIM: 0.75},
+	{preset: "perlin", width: 64, height: 36, spp: 64, minPSNR: 27, minSSIM: 0.9},
+	{preset: "quads", width: 48, height: 48, spp: 64, minPSNR: 27, minSSIM: 0.9},
+}
+
+func renderGolden(t *testing.T, test goldenCase) *imagediff.Image {
+	t.Helper()
+	camera, err := Load(test.preset, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera.SetImageSize(test.width, test.height)
+	camera.SetSamplesPerPixel(test.spp)
+	camera.MaxDepth = 10
+	img, err := imagediff.FromPixels(test.width, test.height, core.Colors2Pixels(camera.RenderToColors()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestGolden(t *testing.T) {
+	for _, test := range goldenCases {
+		t.Run(test.preset, func(t *testing.T) {
+			path := filepath.Join("testdata", "golden", test.preset+".png")
+			actual := renderGolden(t, test)
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := actual.Save(path); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := imagediff.Load(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("%v, run with -update to create it", err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			metrics, err := imagediff.Compare(expected, actual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metrics.PSNR >= test.minPSNR && metrics.SSIM >= test.minSSIM {
+				return
+			}
+			t.Errorf("%s, want PSNR >= %v dB and SSIM >= %v", metrics, test.minPSNR, test.minSSIM)
+			writeGoldenFailure(t, test.preset, expected, actual)
+		})
+	}
+}
+
+// writeGoldenFailure 保存本次渲染与误差热力图，便于和参考图对照
+func writeGoldenFailure(t *testing.T, name string, expected, actual *imagediff.Image) {
+	t.Helper()
+	dir := filepath.Join(os.TempDir(), "golden-failures")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Log(err)
+		return
+	}
+	heatmap, _, err := imagediff.Heatmap(expected, actual, 0)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	actualPath := filepath.Join(dir, name+".actual.png")
+	heatmapPath := filepath.Join(dir, name+".heatmap.png")
+	if err := actual.Save(actualPath); err != nil {
+		t.Log(err)
+	}
+	if err := heatmap.Save(heatmapPath); err != nil {
+		t.Log(err)
+	}
+	t.Logf("wrote %s and %s", actualPath, heatmapPath)
+}
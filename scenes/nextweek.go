@@ -0,0 +1,171 @@
+package scenes
+
+import (
+	"RayTracingInOneWeekend/core"
+	"RayTracingInOneWeekend/utils"
+)
+
+// perlinSpheres 地面和小球都使用Perlin噪声纹理
+func perlinSpheres() (*core.Camera, error) {
+	noise := core.LambertianReflectionMaterial{Tex: core.NewNoiseTexture(4)}
+	camera := core.NewCamera(core.Point{}, core.Point{X: 13, Y: 2, Z: 3}, 16.0/9.0, 20, 400, 100, 50, true, 0, 10)
+	camera.Add(
+		core.NewPlane(core.Point{}, core.Vec3{Y: 1}).WithMaterial(noise),
+		core.NewSphere(core.Point{Y: 2}, 2).WithMaterial(noise),
+	)
+	return camera, nil
+}
+
+// earth 贴了地球图片的球
+func earth() (*core.Camera, error) {
+	texture, err := earthTexture()
+	if err != nil {
+		return nil, err
+	}
+	camera := core.NewCamera(core.Point{}, core.Point{Z: 12}, 16.0/9.0, 20, 400, 100, 50, true, 0, 10)
+	camera.Add(core.NewSphere(core.Point{}, 2).WithMaterial(core.LambertianReflectionMaterial{Tex: texture}))
+	return camera, nil
+}
+
+// quads 围成一圈的五个彩色四边形
+func quads() (*core.Camera, error) {
+	camera := core.NewCamera(core.Point{}, core.Point{Z: 9}, 1, 80, 400, 100, 50, true, 0, 10)
+	camera.Add(
+		core.NewQuad(core.Point{X: -3, Y: -2, Z: 5}, core.Vec3{Z: -4}, core.Vec3{Y: 4}).WithMaterial(lambertian(core.Color{X: 1, Y: 0.2, Z: 0.2})),   // 左
+		core.NewQuad(core.Point{X: -2, Y: -2}, core.Vec3{X: 4}, core.Vec3{Y: 4}).WithMaterial(lambertian(core.Color{X: 0.2, Y: 1, Z: 0.2})),          // 后
+		core.NewQuad(core.Point{X: 3, Y: -2, Z: 1}, core.Vec3{Z: 4}, core.Vec3{Y: 4}).WithMaterial(lambertian(core.Color{X: 0.2, Y: 0.2, Z: 1})),     // 右
+		core.NewQuad(core.Point{X: -2, Y: 3, Z: 1}, core.Vec3{X: 4}, core.Vec3{Z: 4}).WithMaterial(lambertian(core.Color{X: 1, Y: 0.5})),             // 上
+		core.NewQuad(core.Point{X: -2, Y: -3, Z: 5}, core.Vec3{X: 4}, core.Vec3{Z: -4}).WithMaterial(lambertian(core.Color{X: 0.2, Y: 0.8, Z: 0.8})), // 下
+	)
+	return camera, nil
+}
+
+// simpleLight Perlin小球由一个球形光源和一个矩形光源照亮，没有天空光
+func simpleLight() (*core.Camera, error) {
+	noise := core.LambertianReflectionMaterial{Tex: core.NewNoiseTexture(4)}
+	light := core.NewDiffuseLight(core.Color{X: 4, Y: 4, Z: 4})
+	camera := core.NewCamera(core.Point{Y: 2}, core.Point{X: 26, Y: 3, Z: 6}, 16.0/9.0, 20, 400, 100, 50, true, 0, 10)
+	camera.SetBackground(core.Color{})
+	camera.Add(
+		core.NewPlane(core.Point{}, core.Vec3{Y: 1}).WithMaterial(noise),
+		core.NewSphere(core.Point{Y: 2}, 2).WithMaterial(noise),
+		core.NewSphere(core.Point{Y: 7}, 2).WithMaterial(light),
+		core.NewQuad(core.Point{X: 3, Y: 1, Z: -2}, core.Vec3{X: 2}, core.Vec3{Y: 2}).WithMaterial(light),
+	)
+	return camera, nil
+}
+
+var (
+	cornellRed   = lambertian(core.Color{X: .65, Y: .05, Z: .05})
+	cornellWhite = lambertian(core.Color{X: .73, Y: .73, Z: .73})
+	cornellGreen = lambertian(core.Color{X: .12, Y: .45, Z: .15})
+)
+
+// cornellCamera 边长555的Cornell盒子的标准视角
+func cornellCamera() *core.Camera {
+	camera := core.NewCamera(core.Point{X: 278, Y: 278}, core.Point{X: 278, Y: 278, Z: -800}, 1, 40, 600, 200, 50, true, 0, 10)
+	camera.SetBackground(core.Color{})
+	return camera
+}
+
+// cornellWalls 盒子的五面墙（正面开口）和顶部的矩形光源
+func cornellWalls(lightCorner core.Point, lightU, lightV core.Vec3, light core.MaterialI) []core.HittableItemI {
+	return []core.HittableItemI{
+		core.NewQuad(core.Point{X: 555}, core.Vec3{Y: 555}, core.Vec3{Z: 555}).WithMaterial(cornellGreen),
+		core.NewQuad(core.Point{}, core.Vec3{Y: 555}, core.Vec3{Z: 555}).WithMaterial(cornellRed),
+		core.NewQuad(lightCorner, lightU, lightV).WithMaterial(light),
+		core.NewQuad(core.Point{}, core.Vec3{X: 555}, core.Vec3{Z: 555}).WithMaterial(cornellWhite),
+		core.NewQuad(core.Point{X: 555, Y: 555, Z: 555}, core.Vec3{X: -555}, core.Vec3{Z: -555}).WithMaterial(cornellWhite),
+		core.NewQuad(core.Point{Z: 555}, core.Vec3{X: 555}, core.Vec3{Y: 555}).WithMaterial(cornellWhite),
+	}
+}
+
+// cornellBlocks 盒子中一高一矮两个转过一定角度的长方体
+func cornellBlocks() (tall, short core.HittableItemI) {
+	tall = core.NewInstance(core.NewBox(core.Point{}, core.Point{X: 165, Y: 330, Z: 165}).WithMaterial(cornellWhite),
+		core.NewTransform(core.Vec3{X: 265, Z: 295}, core.Vec3{Y: 15}, core.Vec3{X: 1, Y: 1, Z: 1}))
+	short = core.NewInstance(core.NewBox(core.Point{}, core.Point{X: 165, Y: 165, Z: 165}).WithMaterial(cornellWhite),
+		core.NewTransform(core.Vec3{X: 130, Z: 65}, core.Vec3{Y: -18}, core.Vec3{X: 1, Y: 1, Z: 1}))
+	return tall, short
+}
+
+// cornellBox Cornell盒子
+func cornellBox() (*core.Camera, error) {
+	camera := cornellCamera()
+	light := core.NewDiffuseLight(core.Color{X: 15, Y: 15, Z: 15})
+	camera.Add(cornellWalls(core.Point{X: 343, Y: 554, Z: 332}, core.Vec3{X: -130}, core.Vec3{Z: -105}, light)...)
+	tall, short := cornellBlocks()
+	camera.Add(tall, short)
+	return camera, nil
+}
+
+// cornellSmoke 两个长方体换成黑烟和白雾，并使用更大更暗的光源
+func cornellSmoke() (*core.Camera, error) {
+	camera := cornellCamera()
+	light := core.NewDiffuseLight(core.Color{X: 7, Y: 7, Z: 7})
+	camera.Add(cornellWalls(core.Point{X: 113, Y: 554, Z: 127}, core.Vec3{X: 330}, core.Vec3{Z: 305}, light)...)
+	tall, short := cornellBlocks()
+	camera.Add(
+		core.NewConstantMedium(tall, 0.01, core.Color{}),
+		core.NewConstantMedium(short, 0.01, core.Color{X: 1, Y: 1, Z: 1}),
+	)
+	return camera, nil
+}
+
+// nextWeek 《Ray Tracing: The Next Week》最终场景，包含本书的所有特性
+func nextWeek() (*core.Camera, error) {
+	camera := core.NewCamera(core.Point{X: 278, Y: 278}, core.Point{X: 478, Y: 278, Z: -600}, 1, 40, 800, 10000, 40, true, 0, 10)
+	camera.SetBackground(core.Color{})
+
+	// 高低不平的绿色方块地面
+	ground := lambertian(core.Color{X: 0.48, Y: 0.83, Z: 0.53})
+	const boxesPerSide = 20
+	var boxes []core.HittableItemI
+	for i := 0; i < boxesPerSide; i++ {
+		for j := 0; j < boxesPerSide; j++ {
+			w := 100.0
+			x0 := -1000.0 + float64(i)*w
+			z0 := -1000.0 + float64(j)*w
+			y1 := utils.RandomBetween(1, 101)
+			boxes = append(boxes, core.NewBox(core.Point{X: x0, Z: z0}, core.Point{X: x0 + w, Y: y1, Z: z0 + w}).WithMaterial(ground))
+		}
+	}
+	camera.Add(core.NewBVHNode(boxes))
+
+	light := core.NewDiffuseLight(core.Color{X: 7, Y: 7, Z: 7})
+	camera.Add(core.NewQuad(core.Point{X: 123, Y: 554, Z: 147}, core.Vec3{X: 300}, core.Vec3{Z: 265}).WithMaterial(light))
+
+	// 运动模糊的球
+	center := core.Point{X: 400, Y: 400, Z: 200}
+	moving := core.NewSphere(center, 50).WithMaterial(lambertian(core.Color{X: 0.7, Y: 0.3, Z: 0.1}))
+	moving.SetUniformLinearMovement(core.Point(core.Vec3(center).Add(core.Vec3{X: 30})))
+	camera.Add(
+		moving,
+		core.NewSphere(core.Point{X: 260, Y: 150, Z: 45}, 50).WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5}),
+		core.NewSphere(core.Point{Y: 150, Z: 145}, 50).WithMaterial(core.MetalMaterial{Albedo: core.Color{X: 0.8, Y: 0.8, Z: 0.9}, Fuzz: 1.0}),
+	)
+
+	// 内部充满蓝色介质的玻璃球，以及笼罩整个场景的薄雾
+	boundary := core.NewSphere(core.Point{X: 360, Y: 150, Z: 145}, 70).WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5})
+	camera.Add(boundary, core.NewConstantMedium(boundary, 0.2, core.Color{X: 0.2, Y: 0.4, Z: 0.9}))
+	mist := core.NewSphere(core.Point{}, 5000).WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5})
+	camera.Add(core.NewConstantMedium(mist, .0001, core.Color{X: 1, Y: 1, Z: 1}))
+
+	texture, err := earthTexture()
+	if err != nil {
+		return nil, err
+	}
+	camera.Add(core.NewSphere(core.Point{X: 400, Y: 200, Z: 400}, 100).WithMaterial(core.LambertianReflectionMaterial{Tex: texture}))
+	camera.Add(core.NewSphere(core.Point{X: 220, Y: 280, Z: 300}, 80).WithMaterial(core.LambertianReflectionMaterial{Tex: core.NewNoiseTexture(0.2)}))
+
+	// 一千个小球组成的立方体，整体旋转后平移
+	white := lambertian(core.Color{X: .73, Y: .73, Z: .73})
+	var spheres []core.HittableItemI
+	for range 1000 {
+		spheres = append(spheres, core.NewSphere(core.Point(core.RandomBetween(0, 165)), 10).WithMaterial(white))
+	}
+	camera.Add(core.NewInstance(core.NewBVHNode(spheres), core.NewTransform(core.Vec3{X: -100, Y: 270, Z: 395}, core.Vec3{Y: 15}, core.Vec3{X: 1, Y: 1, Z: 1})))
+
+	camera.EnabledBVH(true)
+	return camera, nil
+}
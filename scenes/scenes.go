@@ -0,0 +1,78 @@
+// Package scenes 《Ray Tracing in One Weekend》系列中的预设场景，可以按名称选择。
+// 场景中的随机布局使用全局随机数，Load 会先设置种子，同一种子总是得到相同的场景。
+package scenes
+
+import (
+	"RayTracingInOneWeekend/core"
+	"RayTracingInOneWeekend/utils"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// AssetDir 图片等资源文件所在目录，earth 和 nextweek 场景使用其中的 earthmap.jpg，
+// 没有这张图片时改用程序生成的地球纹理
+var AssetDir = "assets"
+
+// Preset 预设场景
+type Preset struct {
+	Name        string
+	Description string
+	Build       func() (*core.Camera, error)
+}
+
+var presets = []Preset{
+	{"weekend", "final scene of Ray Tracing in One Weekend: random spheres on a checkered ground", weekend},
+	{"checkered", "two checkered spheres", checkeredSpheres},
+	{"perlin", "two spheres with a Perlin noise marble texture", perlinSpheres},
+	{"earth", "a globe with an image texture (assets/earthmap.jpg, or a procedural one when it is missing)", earth},
+	{"quads", "five coloured quads", quads},
+	{"light", "Perlin spheres lit by a sphere light and a quad light", simpleLight},
+	{"cornell", "the Cornell box", cornellBox},
+	{"smoke", "the Cornell box with blocks of smoke and fog", cornellSmoke},
+	{"nextweek", "final scene of Ray Tracing: The Next Week", nextWeek},
+	{"shapes", "analytic primitives and a CSG-carved sphere on an infinite plane", shapes},
+	{"sdf", "sphere-traced distance fields: a Mandelbulb, smooth blends, a twisted box and repeated rings", sdfShapes},
+	{"terrain", "a Perlin noise heightfield with a lake", terrain},
+}
+
+// Presets 所有预设场景
+func Presets() []Preset {
+	return append([]Preset(nil), presets...)
+}
+
+// Names 所有预设场景的名称
+func Names() []string {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Load 设置随机数种子后构建名为name的场景
+func Load(name string, seed int64) (*core.Camera, error) {
+	for _, p := range presets {
+		if p.Name == name {
+			utils.Seed(seed)
+			camera, err := p.Build()
+			if err != nil {
+				return nil, fmt.Errorf("scene %s: %w", name, err)
+			}
+			return camera, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown scene %q (available: %s)", name, strings.Join(Names(), ", "))
+}
+
+func imageTexture(name string) (*core.ImageTexture, error) {
+	texture, err := core.NewImageTexture(filepath.Join(AssetDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("load texture: %w", err)
+	}
+	return texture, nil
+}
+
+func lambertian(c core.Color) core.LambertianReflectionMaterial {
+	return core.LambertianReflectionMaterial{Albedo: c}
+}
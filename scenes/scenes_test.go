@@ -0,0 +1,156 @@
+package scenes
+
+import (
+	"RayTracingInOneWeekend/core"
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeAssets 生成一张渐变图代替earthmap.jpg
+func fakeAssets(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 8), B: 128, A: 255})
+		}
+	}
+	f, err := os.Create(filepath.Join(dir, "earthmap.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := jpeg.Encode(f, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	previous := AssetDir
+	AssetDir = dir
+	t.Cleanup(func() { AssetDir = previous })
+}
+
+func TestPresets(t *testing.T) {
+	fakeAssets(t)
+	for _, preset := range Presets() {
+		camera, err := Load(preset.Name, 1)
+		if err != nil {
+			t.Errorf("%s: %v", preset.Name, err)
+			continue
+		}
+		camera.SetImageSize(16, 16)
+		camera.SetSamplesPerPixel(2)
+		camera.MaxDepth = 4
+		colors := camera.MultithreadedRenderToColors(4, 1000)
+		lit := false
+		for _, c := range colors {
+			if c.X > 0 || c.Y > 0 || c.Z > 0 {
+				lit = true
+				break
+			}
+		}
+		if !lit {
+			t.Errorf("%s rendered a black image", preset.Name)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load("nope", 1); err == nil {
+		t.Error("expected an error for an unknown scene")
+	}
+	previous := AssetDir
+	AssetDir = t.TempDir()
+	defer func() { AssetDir = previous }()
+	if err := os.WriteFile(filepath.Join(AssetDir, "earthmap.jpg"), []byte("not a jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load("earth", 1); err == nil {
+		t.Error("expected an error for a corrupt texture")
+	}
+}
+
+// 没有earthmap.jpg时earth使用程序生成的地球纹理，球面上既有海洋也有陆地
+func TestEarthWithoutAssets(t *testing.T) {
+	previous := AssetDir
+	AssetDir = t.TempDir()
+	defer func() { AssetDir = previous }()
+	for _, name := range []string{"earth", "nextweek"} {
+		if _, err := Load(name, 1); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	texture, err := earthTexture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ocean, land int
+	for j := 1; j < 20; j++ {
+		for i := 0; i < 40; i++ {
+			c := texture.Value(float64(i)/40, float64(j)/20, core.Point{})
+			if c.Z > c.Y {
+				ocean++
+			} else {
+				land++
+			}
+		}
+	}
+	if ocean == 0 || land == 0 {
+		t.Errorf("globe has %d ocean and %d land samples", ocean, land)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	write := func(seed int64) string {
+		camera, err := Load("weekend", seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := core.WriteScene(&buf, camera, core.RenderSettings{}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	if write(7) != write(7) {
+		t.Error("the same seed gave different scenes")
+	}
+	if write(7) == write(8) {
+		t.Error("different seeds gave the same scene")
+	}
+}
+
+// 预设场景可以保存为场景文件并原样加载回来
+func TestPresetsRoundTrip(t *testing.T) {
+	fakeAssets(t)
+	for _, preset := range Presets() {
+		if preset.Name == "sdf" { // 距离函数是闭包，无法序列化
+			continue
+		}
+		camera, err := Load(preset.Name, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", preset.Name, err)
+		}
+		var first bytes.Buffer
+		if err := core.WriteScene(&first, camera, core.RenderSettings{}); err != nil {
+			t.Errorf("%s: %v", preset.Name, err)
+			continue
+		}
+		reloaded, _, err := core.LoadScene(bytes.NewReader(first.Bytes()))
+		if err != nil {
+			t.Errorf("%s: reload: %v", preset.Name, err)
+			continue
+		}
+		var second bytes.Buffer
+		if err := core.WriteScene(&second, reloaded, core.RenderSettings{}); err != nil {
+			t.Errorf("%s: %v", preset.Name, err)
+			continue
+		}
+		if first.String() != second.String() {
+			t.Errorf("%s: round trip changed the scene", preset.Name)
+		}
+	}
+}
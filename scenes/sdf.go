@@ -0,0 +1,47 @@
+package scenes
+
+import "RayTracingInOneWeekend/core"
+
+// sdfShapes 球体追踪渲染的距离场：Mandelbulb分形、平滑融合的小球、扭曲的长方体和一排重复的圆环
+func sdfShapes() (*core.Camera, error) {
+	camera := core.NewCamera(core.Point{Y: 1}, core.Point{Y: 3, Z: 9}, 16.0/9.0, 35, 400, 100, 50, true, 0, 9)
+	camera.SetBackground(core.Color{X: 0.6, Y: 0.7, Z: 0.9})
+	camera.Add(core.NewPlane(core.Point{}, core.Vec3{Y: 1}).WithMaterial(lambertian(core.Color{X: 0.5, Y: 0.5, Z: 0.5})))
+
+	add := func(distance core.SDF, min, max core.Point, stepScale float64, mat core.MaterialI) error {
+		object, err := core.NewSDFObject(distance, core.NewAABBFromPoints(min, max))
+		if err != nil {
+			return err
+		}
+		camera.Add(object.WithStepScale(stepScale).WithMaterial(mat))
+		return nil
+	}
+
+	// 距离估计不是精确距离，步长取0.5
+	bulb := core.MandelbulbSDF(8, 10).Scale(1.2).Translate(core.Vec3{Y: 1.4})
+	if err := add(bulb, core.Point{X: -1.5, Y: -0.1, Z: -1.5}, core.Point{X: 1.5, Y: 2.9, Z: 1.5}, 0.5,
+		core.MetalMaterial{Albedo: core.Color{X: 0.8, Y: 0.5, Z: 0.3}, Fuzz: 0.2}); err != nil {
+		return nil, err
+	}
+
+	blob := core.SphereSDF(0.5).Translate(core.Vec3{X: -3, Y: 0.5}).
+		SmoothUnion(core.SphereSDF(0.35).Translate(core.Vec3{X: -2.5, Y: 0.9, Z: 0.3}), 0.3).
+		SmoothUnion(core.SphereSDF(0.3).Translate(core.Vec3{X: -3.4, Y: 1, Z: 0.2}), 0.3)
+	if err := add(blob, core.Point{X: -4, Y: -0.1, Z: -0.6}, core.Point{X: -2, Y: 1.6, Z: 0.9}, 1,
+		lambertian(core.Color{X: 0.2, Y: 0.6, Z: 0.3})); err != nil {
+		return nil, err
+	}
+
+	twisted := core.BoxSDF(core.Vec3{X: 0.4, Y: 1, Z: 0.4}).Round(0.05).Twist(1.2).Translate(core.Vec3{X: 3, Y: 1.05})
+	if err := add(twisted, core.Point{X: 2.3, Y: -0.1, Z: -0.7}, core.Point{X: 3.7, Y: 2.2, Z: 0.7}, 0.6,
+		lambertian(core.Color{X: 0.7, Y: 0.2, Z: 0.2})); err != nil {
+		return nil, err
+	}
+
+	rings := core.TorusSDF(0.25, 0.08).Repeat(core.Vec3{X: 0.8}).Translate(core.Vec3{Y: 0.08, Z: 2.5})
+	if err := add(rings, core.Point{X: -3.7, Z: 2.1}, core.Point{X: 3.7, Y: 0.2, Z: 2.9}, 1,
+		core.DielectricMaterial{RefractionIndex: 1.5}); err != nil {
+		return nil, err
+	}
+	return camera, nil
+}
@@ -0,0 +1,29 @@
+package scenes
+
+import "RayTracingInOneWeekend/core"
+
+// shapes 无限平面上的圆柱、圆锥、圆环、圆盘，以及用CSG打了三个孔的玻璃球
+func shapes() (*core.Camera, error) {
+	camera := core.NewCamera(core.Point{Y: 0.8}, core.Point{Y: 4, Z: 12}, 16.0/9.0, 30, 400, 100, 50, true, 0, 12)
+	checker := core.NewCheckerTexture(1, core.Color{X: .2, Y: .3, Z: .1}, core.Color{X: .9, Y: .9, Z: .9})
+	camera.Add(core.NewPlane(core.Point{}, core.Vec3{Y: 1}).WithMaterial(core.LambertianReflectionMaterial{Tex: checker}))
+
+	// 三个互相垂直的孔
+	drill := func(rotation core.Vec3) core.HittableItemI {
+		return core.NewInstance(core.NewCylinder(core.Point{Y: -2}, 0.45, 4), core.NewTransform(core.Vec3{}, rotation, core.Vec3{X: 1, Y: 1, Z: 1}))
+	}
+	var carved core.HittableItemI = core.NewSphere(core.Point{}, 1)
+	for _, rotation := range []core.Vec3{{}, {X: 90}, {Z: 90}} {
+		carved = core.NewDifference(carved, drill(rotation))
+	}
+	camera.Add(core.NewInstance(core.NewCSG(core.CSGDifference, carved, core.NewSphere(core.Point{}, 0.3)).WithMaterial(core.MetalMaterial{Albedo: core.Color{X: 0.8, Y: 0.6, Z: 0.2}, Fuzz: 0.1}),
+		core.NewTransform(core.Vec3{Y: 1}, core.Vec3{X: 20, Y: 30}, core.Vec3{X: 1, Y: 1, Z: 1})))
+
+	camera.Add(
+		core.NewCylinder(core.Point{X: -3}, 0.7, 1.6).WithMaterial(lambertian(core.Color{X: 0.7, Y: 0.2, Z: 0.2})),
+		core.NewCone(core.Point{X: 3}, 0.8, 1.8).WithMaterial(lambertian(core.Color{X: 0.2, Y: 0.4, Z: 0.8})),
+		core.NewTorus(core.Point{X: -1.5, Y: 0.3, Z: 2.5}, 0.8, 0.3).WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5}),
+		core.NewDisk(core.Point{X: 2, Y: 0.8, Z: 2.5}, core.Vec3{Y: 1, Z: 1}, 0.7).WithMaterial(lambertian(core.Color{X: 0.9, Y: 0.8, Z: 0.2})),
+	)
+	return camera, nil
+}
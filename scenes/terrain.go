@@ -0,0 +1,33 @@
+package scenes
+
+import "RayTracingInOneWeekend/core"
+
+// terrain 由多层Perlin噪声生成的257×257高度场山地，山谷中是一片湖面
+func terrain() (*core.Camera, error) {
+	const samples = 257
+	perlin := core.NewPerlin()
+	heights := make([]float64, samples*samples)
+	for j := range samples {
+		for i := range samples {
+			// 分形噪声：每层频率加倍、振幅减半
+			height, amplitude := 0.5, 0.5
+			p := core.Vec3{X: float64(i) / 50, Y: 0.5, Z: float64(j) / 50}
+			for range 6 {
+				height += amplitude * perlin.Noise(core.Point(p))
+				amplitude *= 0.5
+				p = p.MultiplicationNum(2)
+			}
+			heights[j*samples+i] = height
+		}
+	}
+	field, err := core.NewHeightfield(heights, samples, samples, core.Point{X: -10, Z: -10}, core.Vec3{X: 20, Y: 4, Z: 20})
+	if err != nil {
+		return nil, err
+	}
+	camera := core.NewCamera(core.Point{Y: 1}, core.Point{Y: 10, Z: 20}, 16.0/9.0, 40, 400, 100, 50, true, 0, 20)
+	camera.Add(
+		field.WithMaterial(lambertian(core.Color{X: 0.45, Y: 0.4, Z: 0.3})),
+		core.NewQuad(core.Point{X: -10, Y: 1.6, Z: -10}, core.Vec3{Z: 20}, core.Vec3{X: 20}).WithMaterial(core.MetalMaterial{Albedo: core.Color{X: 0.5, Y: 0.6, Z: 0.7}, Fuzz: 0.05}),
+	)
+	return camera, nil
+}
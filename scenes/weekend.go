@@ -0,0 +1,64 @@
+package scenes
+
+import (
+	"RayTracingInOneWeekend/core"
+	"RayTracingInOneWeekend/utils"
+)
+
+// weekend 《Ray Tracing in One Weekend》最终场景：棋盘格地面上的随机小球，漫反射小球带有向上的运动模糊
+func weekend() (*core.Camera, error) {
+	// 材质
+	// 网格纹理
+	checkerTexture := core.NewCheckerTexture(0.32, core.Color{X: .2, Y: .3, Z: .1}, core.Color{X: .9, Y: .9, Z: .9})
+	groundMaterial := core.LambertianReflectionMaterial{Albedo: core.Color{X: 0.5, Y: 0.5, Z: 0.5}, Tex: checkerTexture}
+	ground := core.NewPlane(core.Point{}, core.Vec3{Y: 1}).WithMaterial(groundMaterial)
+	glass := core.NewSphere(core.Point{Y: 1}, 1.0).WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5})
+	diffuse := core.NewSphere(core.Point{X: -4, Y: 1}, 1.0).WithMaterial(lambertian(core.Color{X: 0.4, Y: 0.2, Z: 0.1}))
+	metal := core.NewSphere(core.Point{X: 4, Y: 1}, 1.0).WithMaterial(core.MetalMaterial{Albedo: core.Color{X: 0.7, Y: 0.6, Z: 0.5}})
+	// 相机构建
+	camera := core.NewCamera(core.Point{}, core.Point{X: 13, Y: 2, Z: 3}, 16.0/9.0, 20, 400, 100, 50, true, 0.6, 10.0)
+	camera.Add(ground, glass, diffuse, metal)
+	// 随机构建场景
+	for i := -11; i < 11; i++ {
+		for j := -11; j < 11; j++ {
+			chooseMat := utils.Random()
+			center := core.Point{X: float64(i) + 0.9*utils.Random(), Y: 0.2, Z: float64(j) + 0.9*utils.Random()}
+			if core.Vec3(center).Sub(core.Vec3{X: 4.0, Y: 0.2}).Length() <= 0.9 {
+				continue
+			}
+			sphere := core.NewSphere(center, 0.2)
+			switch {
+			case chooseMat < 0.8:
+				// diffuse
+				albedo := core.Color(core.Random().MultiplicationVec3(core.Random()))
+				sphere.WithMaterial(lambertian(albedo))
+				sphere.SetUniformLinearMovement(core.Point(core.Vec3(center).Add(core.Vec3{Y: utils.RandomBetween(0, 0.5)})))
+			case chooseMat < 0.95:
+				// metal
+				sphere.WithMaterial(core.MetalMaterial{
+					Albedo: core.Color(core.RandomBetween(0.5, 1)),
+					Fuzz:   utils.RandomBetween(0, 0.5),
+				})
+			default:
+				// glass
+				sphere.WithMaterial(core.DielectricMaterial{RefractionIndex: 1.5})
+			}
+			camera.Add(sphere)
+		}
+	}
+	camera.EnabledBVH(true)
+	return camera, nil
+}
+
+// checkeredSpheres 上下两个棋盘格纹理的大球
+func checkeredSpheres() (*core.Camera, error) {
+	checker := core.LambertianReflectionMaterial{
+		Tex: core.NewCheckerTexture(0.32, core.Color{X: .2, Y: .3, Z: .1}, core.Color{X: .9, Y: .9, Z: .9}),
+	}
+	camera := core.NewCamera(core.Point{}, core.Point{X: 13, Y: 2, Z: 3}, 16.0/9.0, 20, 400, 100, 50, true, 0, 10)
+	camera.Add(
+		core.NewSphere(core.Point{Y: -10}, 10).WithMaterial(checker),
+		core.NewSphere(core.Point{Y: 10}, 10).WithMaterial(checker),
+	)
+	return camera, nil
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+)
+
+// SaveGIF 将多帧像素保存为循环播放的GIF动画，delay为每帧时长（单位1/100秒）
+// GIF只有256色，使用Plan9调色板并做Floyd-Steinberg抖动，适合快速预览
+func SaveGIF(path string, width, height int, frames [][]Pixel, delay int) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("gif %s: no frames", path)
+	}
+	anim := &gif.GIF{LoopCount: 0}
+	bounds := image.Rect(0, 0, width, height)
+	for n, pixels := range frames {
+		if len(pixels) != width*height {
+			return fmt.Errorf("gif %s: frame %d has %d pixels, want %d", path, n, len(pixels), width*height)
+		}
+		rgba := ToRGBA(width, height, pixels)
+		paletted := image.NewPaletted(bounds, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, bounds, rgba, image.Point{})
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("gif %s: %w", path, err)
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		return fmt.Errorf("gif %s: %w", path, err)
+	}
+	return f.Close()
+}
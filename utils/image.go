@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImageFormats 支持按扩展名保存的格式
+var ImageFormats = []string{".ppm", ".png", ".jpg", ".jpeg"}
+
+// ToRGBA 将按行存储的像素转换为image.RGBA
+func ToRGBA(width, height int, pixels []Pixel) *image.RGBA {
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			p := pixels[j*width+i]
+			rgba.SetRGBA(i, j, color.RGBA{R: uint8(p.R), G: uint8(p.G), B: uint8(p.B), A: 255})
+		}
+	}
+	return rgba
+}
+
+// ImageFormat 返回path的扩展名（小写），不支持的格式返回错误
+func ImageFormat(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, format := range ImageFormats {
+		if ext == format {
+			return ext, nil
+		}
+	}
+	return "", fmt.Errorf("save %s: unsupported image format %q (supported: %s)", path, ext, strings.Join(ImageFormats, ", "))
+}
+
+// EncodeImage 按格式（ImageFormats中的扩展名）把像素编码写入w，PPM写出8位二进制P6
+func EncodeImage(w io.Writer, format string, width, height int, pixels []Pixel) error {
+	if len(pixels) != width*height {
+		return fmt.Errorf("got %d pixels for a %dx%d image", len(pixels), width, height)
+	}
+	switch strings.ToLower(format) {
+	case ".ppm":
+		ppm := PPMImage{Width: width, Height: height, Max: 255, Pixels: pixels, Binary: true}
+		return ppm.Encode(w)
+	case ".png":
+		return png.Encode(w, ToRGBA(width, height, pixels))
+	case ".jpg", ".jpeg":
+		return jpeg.Encode(w, ToRGBA(width, height, pixels), &jpeg.Options{Quality: 95})
+	}
+	return fmt.Errorf("unsupported image format %q (supported: %s)", format, strings.Join(ImageFormats, ", "))
+}
+
+// SaveImage 按path的扩展名（.ppm、.png、.jpg）选择格式保存像素
+func SaveImage(path string, width, height int, pixels []Pixel) (err error) {
+	ext, err := ImageFormat(path)
+	if err != nil {
+		return err
+	}
+	if len(pixels) != width*height {
+		return fmt.Errorf("save %s: got %d pixels for a %dx%d image", path, len(pixels), width, height)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("save %s: %w", path, closeErr)
+		}
+	}()
+	w := bufio.NewWriter(f)
+	if err = EncodeImage(w, ext, width, height, pixels); err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveImage(t *testing.T) {
+	dir := t.TempDir()
+	pixels := []Pixel{{255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {255, 255, 255}, {0, 0, 0}, {128, 128, 128}}
+	for _, name := range []string{"a.ppm", "a.png", "a.jpg", "A.PNG"} {
+		if err := SaveImage(filepath.Join(dir, name), 3, 2, pixels); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	f, err := os.Open(filepath.Join(dir, "a.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, _ := img.At(1, 0).RGBA(); r != 0 || g != 0xffff || b != 0 {
+		t.Errorf("pixel (1,0) = %d %d %d", r, g, b)
+	}
+	if err := SaveImage(filepath.Join(dir, "a.bmp"), 3, 2, pixels); err == nil {
+		t.Error("expected an error for .bmp")
+	}
+	if err := SaveImage(filepath.Join(dir, "b.png"), 4, 2, pixels); err == nil {
+		t.Error("expected an error for a pixel count mismatch")
+	}
+	if err := SaveImage(filepath.Join(dir, "missing", "c.png"), 3, 2, pixels); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	pixels := []Pixel{{255, 0, 0}, {0, 255, 0}}
+	for _, format := range []string{".ppm", ".png", ".jpg"} {
+		var buf bytes.Buffer
+		if err := EncodeImage(&buf, format, 2, 1, pixels); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		img, _, err := image.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 1 {
+			t.Errorf("%s: bounds %v", format, img.Bounds())
+		}
+	}
+	var buf bytes.Buffer
+	if err := EncodeImage(&buf, ".bmp", 2, 1, pixels); err == nil {
+		t.Error("expected an error for .bmp")
+	}
+	if err := EncodeImage(&buf, ".png", 3, 1, pixels); err == nil {
+		t.Error("expected an error for a pixel count mismatch")
+	}
+}
+
+func TestSeed(t *testing.T) {
+	Seed(42)
+	a := []float64{Random(), RandomBetween(1, 2), float64(RandomInt(0, 100))}
+	Seed(42)
+	b := []float64{Random(), RandomBetween(1, 2), float64(RandomInt(0, 100))}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed gave %v and %v", a, b)
+		}
+	}
+}
@@ -1,13 +1,11 @@
-// 使用模版生成PPM文件
+// PPM图片的内存表示与读写
 
 package utils
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"strconv"
-	"strings"
-	"text/template"
 )
 
 // 像素结构体
@@ -19,38 +17,32 @@ func (pix Pixel) GetString() string {
 	return fmt.Sprintf("%d %d %d ", pix.R, pix.G, pix.B)
 }
 
-// 定义 PPM 模板
-const ppmTemplate = `P3
-{{.Width}} {{.Height}}
-{{.Max}}
-{{range $i, $p := .Pixels}}
-{{- if $i}}{{if mod $i $.Width}} {{else}}
-{{end}}{{end -}}
-{{$p.R}} {{$p.G}} {{$p.B -}}
-{{end}}
-`
-
 // PPM 图像结构体
 type PPMImage struct {
 	Width  int     // 图像宽度
 	Height int     // 图像高度
-	Max    int     // 最大颜色值 (0-255)
+	Max    int     // 最大颜色值 (1-65535)，大于255时二进制格式每个通道占两个字节
 	Pixels []Pixel // 像素数据 (按行存储)
+	Binary bool    // 二进制P6格式，否则为文本P3
 }
 
-func NewPPMImage(width, height, max int) *PPMImage {
+// NewPPMImage max<=0时使用255
+func NewPPMImage(width, height, max int) (*PPMImage, error) {
 	if max <= 0 {
 		max = 255
 	}
 	if width <= 0 || height <= 0 {
-		panic("宽高不能为负数")
+		return nil, fmt.Errorf("ppm: invalid image size %dx%d", width, height)
+	}
+	if max > 65535 {
+		return nil, fmt.Errorf("ppm: max value %d is larger than 65535", max)
 	}
 	return &PPMImage{
 		Width:  width,
 		Height: height,
 		Max:    max,
 		Pixels: make([]Pixel, 0, width*height),
-	}
+	}, nil
 }
 
 func (p *PPMImage) Full(pix []Pixel) {
@@ -61,68 +53,34 @@ func (p *PPMImage) Full(pix []Pixel) {
 	}
 }
 
-func (p *PPMImage) WriteAndSave(path string) {
-	if len(p.Pixels) == 0 {
-		panic("尚未填充像素无法保存")
-	}
-	// 注册自定义函数 (用于像素换行)
-	funcMap := template.FuncMap{
-		"mod": func(a, b int) int { return a % b },
-	}
-
-	// 解析模板
-	tmpl, err := template.New("ppm").Funcs(funcMap).Parse(ppmTemplate)
-	if err != nil {
-		panic(err)
+// Encode 写出到w，像素数量必须与宽高一致
+func (p *PPMImage) Encode(w io.Writer) error {
+	if len(p.Pixels) != p.Width*p.Height {
+		return fmt.Errorf("ppm: got %d pixels for a %dx%d image", len(p.Pixels), p.Width, p.Height)
 	}
-
-	// 生成文件
-	f, err := os.Create(path + "output.ppm")
+	writer, err := NewPPMWriter(w, p.Width, p.Height, p.Max, p.Binary)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(f)
-
-	// 执行模板写入
-	if err := tmpl.Execute(f, p); err != nil {
-		panic(err)
+	if err := writer.Write(p.Pixels...); err != nil {
+		return err
 	}
+	return writer.Close()
 }
 
-func (p *PPMImage) FastWriteAndSave(name string) {
-	if len(p.Pixels) == 0 {
-		panic("尚未填充像素无法保存")
-	}
-
-	// 生成文件
-	f, err := os.Create(name + ".ppm")
+// Save 保存到path，不会修改扩展名
+func (p *PPMImage) Save(path string) (err error) {
+	f, err := os.Create(path)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("save %s: %w", path, err)
 	}
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(f)
-
-	// 写入文件头
-	fmt.Fprintf(f, "P3\n%d %d\n%d\n", p.Width, p.Height, p.Max)
-	for j := 0; j < p.Height; j++ {
-		var b strings.Builder
-		b.Grow(p.Width * len(strconv.Itoa(p.Max)) * 4)
-		for i := 0; i < p.Width; i++ {
-			index := i + j*p.Width
-			//fmt.Printf("w:%d,h%d index is %d\n", i, j, index)
-			b.WriteString(p.Pixels[index].GetString())
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("save %s: %w", path, closeErr)
 		}
-		fmt.Fprintf(f, "%s\n", b.String())
+	}()
+	if err := p.Encode(f); err != nil {
+		return fmt.Errorf("save %s: %w", path, err)
 	}
-	// 确保文件以换行符结束
-	fmt.Fprint(f, "\n")
+	return nil
 }
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+)
+
+func init() {
+	image.RegisterFormat("ppm", "P3", DecodePPM, decodePPMConfig)
+	image.RegisterFormat("ppm", "P6", DecodePPM, decodePPMConfig)
+}
+
+// ReadPPM 读取P3（文本）或P6（二进制）格式的PPM，保留原始的最大值与通道值，最大值大于255时按16位读取
+func ReadPPM(r io.Reader) (*PPMImage, error) {
+	br := bufio.NewReader(r)
+	p, err := readPPMHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	var sample func() (int, error)
+	if !p.Binary {
+		sample = func() (int, error) {
+			token, err := ppmToken(br)
+			if err != nil {
+				return 0, err
+			}
+			return strconv.Atoi(token)
+		}
+	} else {
+		// 读取最大值时已经消耗了其后唯一的空白字符，接下来就是像素数据
+		bytesPerSample := 1
+		if p.Max > 255 {
+			bytesPerSample = 2
+		}
+		buf := make([]byte, bytesPerSample)
+		sample = func() (int, error) {
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return 0, err
+			}
+			if bytesPerSample == 2 {
+				return int(buf[0])<<8 | int(buf[1]), nil
+			}
+			return int(buf[0]), nil
+		}
+	}
+	// 逐行读取后追加，头部声明的尺寸再大，分配的内存也不会超过实际读到的数据
+	row := make([]Pixel, p.Width)
+	for y := 0; y < p.Height; y++ {
+		for x := range row {
+			var rgb [3]int
+			for c := range rgb {
+				v, err := sample()
+				if err != nil {
+					return nil, fmt.Errorf("ppm: read pixel (%d, %d): %w", x, y, unexpectedEOF(err))
+				}
+				if v < 0 || v > p.Max {
+					return nil, fmt.Errorf("ppm: pixel (%d, %d) value %d is out of range [0, %d]", x, y, v, p.Max)
+				}
+				rgb[c] = v
+			}
+			row[x] = Pixel{R: rgb[0], G: rgb[1], B: rgb[2]}
+		}
+		p.Pixels = append(p.Pixels, row...)
+	}
+	return p, nil
+}
+
+// DecodePPM 读取PPM并转换为16位的image.Image，已注册到image.Decode
+func DecodePPM(r io.Reader) (image.Image, error) {
+	p, err := ReadPPM(r)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA64(image.Rect(0, 0, p.Width, p.Height))
+	scale := func(v int) uint16 { return uint16(v * 65535 / p.Max) }
+	for index, pix := range p.Pixels {
+		img.SetRGBA64(index%p.Width, index/p.Width, color.RGBA64{R: scale(pix.R), G: scale(pix.G), B: scale(pix.B), A: 65535})
+	}
+	return img, nil
+}
+
+func decodePPMConfig(r io.Reader) (image.Config, error) {
+	p, err := readPPMHeader(bufio.NewReader(r))
+	if err != nil {
+		return image.Config{}, err
+	}
+	return image.Config{ColorModel: color.RGBA64Model, Width: p.Width, Height: p.Height}, nil
+}
+
+// readPPMHeader 读取格式、宽高与最大值
+func readPPMHeader(br *bufio.Reader) (*PPMImage, error) {
+	magic, err := ppmToken(br)
+	if err != nil {
+		return nil, fmt.Errorf("ppm: read header: %w", err)
+	}
+	if magic != "P3" && magic != "P6" {
+		return nil, fmt.Errorf("ppm: unsupported magic number %q, expected P3 or P6", magic)
+	}
+	var header [3]int // 宽、高、最大值
+	for i, name := range []string{"width", "height", "max value"} {
+		token, err := ppmToken(br)
+		if err != nil {
+			return nil, fmt.Errorf("ppm: read %s: %w", name, err)
+		}
+		header[i], err = strconv.Atoi(token)
+		if err != nil || header[i] <= 0 {
+			return nil, fmt.Errorf("ppm: invalid %s %q", name, token)
+		}
+	}
+	p := &PPMImage{Width: header[0], Height: header[1], Max: header[2], Binary: magic == "P6"}
+	if p.Max > 65535 {
+		return nil, fmt.Errorf("ppm: max value %d is larger than 65535", p.Max)
+	}
+	if p.Width > 1<<15 || p.Height > 1<<15 {
+		return nil, fmt.Errorf("ppm: image size %dx%d is too large", p.Width, p.Height)
+	}
+	return p, nil
+}
+
+// ppmToken 读取下一个以空白分隔的记号，跳过#开头的注释
+func ppmToken(br *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF && len(token) > 0 {
+			return string(token), nil
+		}
+		if err != nil {
+			return "", unexpectedEOF(err)
+		}
+		switch {
+		case b == '#' && len(token) == 0:
+			if _, err := br.ReadString('\n'); err != nil {
+				return "", unexpectedEOF(err)
+			}
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f':
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, b)
+		}
+	}
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// PPMWriter 逐像素写出PPM，不需要先把整张图放进内存，适合边渲染边输出大图。
+// Binary为true时写出P6，Max大于255时每个通道占两个字节（大端序），否则写出文本P3
+type PPMWriter struct {
+	w             *bufio.Writer
+	width, height int
+	max           int
+	binary        bool
+	written       int // 已写出的像素数
+	column        int // 文本格式当前行已写出的像素数
+	buf           []byte
+	err           error
+}
+
+// NewPPMWriter 检查参数并写出文件头
+func NewPPMWriter(w io.Writer, width, height, max int, binary bool) (*PPMWriter, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("ppm: invalid image size %dx%d", width, height)
+	}
+	if max <= 0 || max > 65535 {
+		return nil, fmt.Errorf("ppm: max value %d is out of range [1, 65535]", max)
+	}
+	p := &PPMWriter{
+		w:      bufio.NewWriter(w),
+		width:  width,
+		height: height,
+		max:    max,
+		binary: binary,
+	}
+	magic := "P3"
+	if binary {
+		magic = "P6"
+	}
+	if _, err := fmt.Fprintf(p.w, "%s\n%d %d\n%d\n", magic, width, height, max); err != nil {
+		return nil, fmt.Errorf("ppm: write header: %w", err)
+	}
+	return p, nil
+}
+
+// Write 按行顺序写出像素，通道值必须在[0, Max]内
+func (p *PPMWriter) Write(pixels ...Pixel) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.written+len(pixels) > p.width*p.height {
+		return fmt.Errorf("ppm: got more than %d pixels for a %dx%d image", p.width*p.height, p.width, p.height)
+	}
+	for _, pix := range pixels {
+		for _, v := range [3]int{pix.R, pix.G, pix.B} {
+			if v < 0 || v > p.max {
+				return fmt.Errorf("ppm: pixel (%d, %d) value %d is out of range [0, %d]", p.written%p.width, p.written/p.width, v, p.max)
+			}
+		}
+		p.buf = p.buf[:0]
+		switch {
+		case !p.binary:
+			if p.column > 0 {
+				p.buf = append(p.buf, ' ')
+			}
+			p.buf = strconv.AppendInt(p.buf, int64(pix.R), 10)
+			p.buf = append(p.buf, ' ')
+			p.buf = strconv.AppendInt(p.buf, int64(pix.G), 10)
+			p.buf = append(p.buf, ' ')
+			p.buf = strconv.AppendInt(p.buf, int64(pix.B), 10)
+			p.column++
+			if p.column == p.width {
+				p.buf = append(p.buf, '\n')
+				p.column = 0
+			}
+		case p.max > 255:
+			p.buf = append(p.buf, byte(pix.R>>8), byte(pix.R), byte(pix.G>>8), byte(pix.G), byte(pix.B>>8), byte(pix.B))
+		default:
+			p.buf = append(p.buf, byte(pix.R), byte(pix.G), byte(pix.B))
+		}
+		if _, err := p.w.Write(p.buf); err != nil {
+			p.err = fmt.Errorf("ppm: write pixels: %w", err)
+			return p.err
+		}
+		p.written++
+	}
+	return nil
+}
+
+// Close 刷新缓冲，像素数量不足时返回错误。不会关闭底层的io.Writer
+func (p *PPMWriter) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.written != p.width*p.height {
+		return fmt.Errorf("ppm: wrote %d of %d pixels", p.written, p.width*p.height)
+	}
+	if err := p.w.Flush(); err != nil {
+		return fmt.Errorf("ppm: write pixels: %w", err)
+	}
+	return nil
+}
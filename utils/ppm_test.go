@@ -1,10 +1,25 @@
 package utils
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
 
-func TestPPMImage_WriteAndSave(t *testing.T) {
+func TestPPMImage_Encode(t *testing.T) {
 	w, h := 256, 256
-	ppmImage := NewPPMImage(w, h, 255)
+	ppmImage, err := NewPPMImage(w, h, 255)
+	if err != nil {
+		t.Fatal(err)
+	}
 	var pix = make([]Pixel, w*h)
 	for i := 0; i < w; i++ {
 		for j := 0; j < h; j++ {
@@ -16,11 +31,184 @@ func TestPPMImage_WriteAndSave(t *testing.T) {
 		}
 	}
 	ppmImage.Full(pix)
-	//ppmImage.WriteAndSave("")
-	ppmImage.FastWriteAndSave("")
-	// P3
-	// 2 2
-	// 255
-	// 255 0 0 0 255 0
-	// 0 255 0 255 0 0
+	path := filepath.Join(t.TempDir(), "gradient.ppm")
+	if err := ppmImage.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	read, err := ReadPPM(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(read.Pixels, pix) {
+		t.Error("saved pixels differ")
+	}
+
+	small, _ := NewPPMImage(2, 2, 255)
+	small.Full([]Pixel{{255, 0, 0}, {0, 255, 0}, {0, 255, 0}, {255, 0, 0}})
+	var buf bytes.Buffer
+	if err := small.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "P3\n2 2\n255\n255 0 0 0 255 0\n0 255 0 255 0 0\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPPMImageErrors(t *testing.T) {
+	if _, err := NewPPMImage(0, 2, 255); err == nil {
+		t.Error("expected an error for an empty image")
+	}
+	if _, err := NewPPMImage(2, 2, 70000); err == nil {
+		t.Error("expected an error for max value 70000")
+	}
+	p, _ := NewPPMImage(2, 2, 255)
+	p.Full([]Pixel{{}, {}})
+	if err := p.Encode(io.Discard); err == nil || !strings.Contains(err.Error(), "got 2 pixels for a 2x2 image") {
+		t.Errorf("err = %v", err)
+	}
+	p.Full(make([]Pixel, 4))
+	path := filepath.Join(t.TempDir(), "missing", "a.ppm")
+	err := p.Save(path)
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), path) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// 头部声明32768x32768但只有一行数据的文件，不能按头部尺寸预先分配约24GiB的像素
+func TestReadPPMTruncatedHugeHeader(t *testing.T) {
+	data := append([]byte("P6 32768 32768 255\n"), make([]byte, 3*32768)...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := ReadPPM(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "read pixel (0, 1)") {
+		t.Errorf("err = %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("allocated %d MiB for a truncated file", allocated>>20)
+	}
+}
+
+func TestDecodePPM(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"P3", "P3\n# comment\n2 1\n255\n255 0 51  0 255 0\n"},
+		{"P6", "P6 2 1 255\n\xff\x00\x33\x00\xff\x00"},
+		{"P6 16-bit", "P6\n2 1\n65535\n\xff\xff\x00\x00\x33\x33\x00\x00\xff\xff\x00\x00"},
+	}
+	for _, test := range tests {
+		img, err := DecodePPM(strings.NewReader(test.data))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 1 {
+			t.Errorf("%s: bounds %v", test.name, img.Bounds())
+			continue
+		}
+		r, g, b, _ := img.At(0, 0).RGBA()
+		r2, g2, _, _ := img.At(1, 0).RGBA()
+		if r != 0xffff || g != 0 || b != 0x3333 || r2 != 0 || g2 != 0xffff {
+			t.Errorf("%s: pixels %x %x %x, %x %x", test.name, r, g, b, r2, g2)
+		}
+	}
+
+	errorTests := []struct {
+		data string
+		want string
+	}{
+		{"P5\n1 1\n255\n\x00", `unsupported magic number "P5"`},
+		{"P3\n1 x\n255\n", `invalid height "x"`},
+		{"P3\n1 1\n70000\n", "max value 70000 is larger than 65535"},
+		{"P3\n1 1\n255\n0 300 0\n", "pixel (0, 0) value 300 is out of range [0, 255]"},
+		{"P3\n2 1\n255\n0 0 0 1\n", "read pixel (1, 0): unexpected EOF"},
+		{"P6\n1 1\n255\n\x00\x00", "read pixel (0, 0): unexpected EOF"},
+		{"P3\n", "read width: unexpected EOF"},
+	}
+	for _, test := range errorTests {
+		_, err := DecodePPM(strings.NewReader(test.data))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q: got %v, want %q", test.data, err, test.want)
+		}
+	}
+}
+
+func TestPPMWriter(t *testing.T) {
+	pixels := []Pixel{{R: 0, G: 1, B: 2}, {R: 255, G: 128, B: 7}, {R: 9, G: 10, B: 11}, {R: 12, G: 13, B: 14}}
+	wide := []Pixel{{R: 0, G: 256, B: 65535}, {R: 1000, G: 2, B: 3}, {R: 4, G: 5, B: 6}, {R: 7, G: 8, B: 60000}}
+	tests := []struct {
+		name   string
+		max    int
+		binary bool
+		pixels []Pixel
+		size   int // 文件字节数，文本格式不检查
+	}{
+		{"P3", 255, false, pixels, 0},
+		{"P6", 255, true, pixels, len("P6\n2 2\n255\n") + 4*3},
+		{"P3 16-bit", 65535, false, wide, 0},
+		{"P6 16-bit", 65535, true, wide, len("P6\n2 2\n65535\n") + 4*6},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		writer, err := NewPPMWriter(&buf, 2, 2, test.max, test.binary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 逐行写入
+		if err := writer.Write(test.pixels[:2]...); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Write(test.pixels[2:]...); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if test.size != 0 && buf.Len() != test.size {
+			t.Errorf("%s: wrote %d bytes, want %d", test.name, buf.Len(), test.size)
+		}
+		p, err := ReadPPM(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Errorf("%s: %v\n%q", test.name, err, buf.String())
+			continue
+		}
+		if p.Width != 2 || p.Height != 2 || p.Max != test.max || p.Binary != test.binary || !slices.Equal(p.Pixels, test.pixels) {
+			t.Errorf("%s: read back %+v", test.name, p)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := NewPPMWriter(&buf, 0, 1, 255, false); err == nil {
+		t.Error("expected an error for an empty image")
+	}
+	if _, err := NewPPMWriter(&buf, 1, 1, 70000, true); err == nil {
+		t.Error("expected an error for max value 70000")
+	}
+	writer, _ := NewPPMWriter(&buf, 2, 1, 255, true)
+	if err := writer.Write(Pixel{R: 256}); err == nil || !strings.Contains(err.Error(), "value 256 is out of range [0, 255]") {
+		t.Errorf("err = %v", err)
+	}
+	if err := writer.Write(Pixel{}, Pixel{}, Pixel{}); err == nil {
+		t.Error("expected an error for too many pixels")
+	}
+	if err := writer.Close(); err == nil || !strings.Contains(err.Error(), "wrote 0 of 2 pixels") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestDecodeRegistered(t *testing.T) {
+	img, format, err := image.Decode(strings.NewReader("P6 1 1 255\n\x10\x20\x30"))
+	if err != nil || format != "ppm" {
+		t.Fatalf("format %q, err %v", format, err)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0x1010 {
+		t.Errorf("r = %x", r)
+	}
 }
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+进度汇报：
+渲染器只和 ProgressTracker 打交道，每完成一个像素调用一次 Add，计数是原子操作，
+只有距离上次汇报超过汇报间隔时才会调用一次 ProgressReporter，结束时调用 Finish 汇报最终进度。
+ProgressReporter 的调用是串行的，实现不需要加锁。
+*/
+
+// DefaultProgressInterval 默认的最小汇报间隔
+const DefaultProgressInterval = 200 * time.Millisecond
+
+// Progress 某一时刻的进度
+type Progress struct {
+	Done    int64         // 已完成任务量
+	Total   int64         // 总任务量
+	Elapsed time.Duration // 已耗时
+}
+
+// Fraction 完成比例[0, 1]
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 1
+	}
+	return min(float64(p.Done)/float64(p.Total), 1)
+}
+
+// ETA 按当前速度估计的剩余时间，还没有完成任何任务时无法估计，返回false
+func (p Progress) ETA() (time.Duration, bool) {
+	if p.Done >= p.Total {
+		return 0, true
+	}
+	if p.Done <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(p.Elapsed) * float64(p.Total-p.Done) / float64(p.Done)), true
+}
+
+// ProgressReporter 进度的输出方式
+type ProgressReporter interface {
+	Report(p Progress) // 进度更新，调用频率受 ProgressTracker 限制
+	Finish(p Progress) // 全部完成，只调用一次
+}
+
+// ProgressTracker 并发安全的进度计数，按间隔限流后转发给 ProgressReporter
+type ProgressTracker struct {
+	total    int64
+	done     int64 // 原子访问
+	next     int64 // 下次允许汇报的时刻（相对start的纳秒），原子访问
+	interval time.Duration
+	start    time.Time
+	reporter ProgressReporter
+	mu       sync.Mutex // 保证汇报串行
+	reported int64      // 最近一次汇报的完成量，防止乱序汇报使进度倒退
+	finished bool
+}
+
+// NewProgressTracker reporter为nil时不汇报，interval<=0时使用 DefaultProgressInterval
+func NewProgressTracker(total int64, reporter ProgressReporter, interval time.Duration) *ProgressTracker {
+	if reporter == nil {
+		reporter = SilentProgress{}
+	}
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	t := &ProgressTracker{
+		total:    total,
+		interval: interval,
+		start:    time.Now(),
+		reporter: reporter,
+	}
+	t.report(Progress{Total: total})
+	t.next = int64(interval)
+	return t
+}
+
+// Add 完成n个任务，可在多个协程中调用
+func (t *ProgressTracker) Add(n int64) {
+	done := atomic.AddInt64(&t.done, n)
+	elapsed := time.Since(t.start)
+	next := atomic.LoadInt64(&t.next)
+	if int64(elapsed) < next {
+		return
+	}
+	// 同一间隔内只有一个协程能抢到汇报权
+	if !atomic.CompareAndSwapInt64(&t.next, next, int64(elapsed+t.interval)) {
+		return
+	}
+	t.report(Progress{Done: min(done, t.total), Total: t.total, Elapsed: elapsed})
+}
+
+// Finish 汇报最终进度，重复调用无效
+func (t *ProgressTracker) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+	t.finished = true
+	done := min(atomic.LoadInt64(&t.done), t.total)
+	t.reporter.Finish(Progress{Done: done, Total: t.total, Elapsed: time.Since(t.start)})
+}
+
+func (t *ProgressTracker) report(p Progress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished || p.Done < t.reported {
+		return
+	}
+	t.reported = p.Done
+	t.reporter.Report(p)
+}
+
+// SilentProgress 不输出任何进度
+type SilentProgress struct{}
+
+func (SilentProgress) Report(Progress) {}
+func (SilentProgress) Finish(Progress) {}
+
+// JSONProgress 每次汇报输出一行JSON，便于服务和日志系统解析
+//
+//	{"done":1200,"total":40000,"percent":3,"elapsed":0.4,"eta":12.93,"finished":false}
+type JSONProgress struct {
+	encoder *json.Encoder
+	err     error
+}
+
+type progressJSON struct {
+	Done     int64    `json:"done"`
+	Total    int64    `json:"total"`
+	Percent  float64  `json:"percent"`
+	Elapsed  float64  `json:"elapsed"`       // 秒
+	ETA      *float64 `json:"eta,omitempty"` // 秒，无法估计时省略
+	Finished bool     `json:"finished"`
+}
+
+func NewJSONProgress(w io.Writer) *JSONProgress {
+	return &JSONProgress{encoder: json.NewEncoder(w)}
+}
+
+func (j *JSONProgress) Report(p Progress) {
+	j.write(p, false)
+}
+
+func (j *JSONProgress) Finish(p Progress) {
+	j.write(p, true)
+}
+
+// Err 返回第一次写入失败的错误
+func (j *JSONProgress) Err() error {
+	return j.err
+}
+
+func (j *JSONProgress) write(p Progress, finished bool) {
+	if j.err != nil {
+		return
+	}
+	line := progressJSON{
+		Done:     p.Done,
+		Total:    p.Total,
+		Percent:  roundTo(p.Fraction()*100, 2),
+		Elapsed:  roundTo(p.Elapsed.Seconds(), 3),
+		Finished: finished,
+	}
+	if eta, ok := p.ETA(); ok {
+		seconds := roundTo(eta.Seconds(), 3)
+		line.ETA = &seconds
+	}
+	j.err = j.encoder.Encode(line)
+}
+
+func roundTo(x float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(x*scale) / scale
+}
@@ -2,21 +2,107 @@ package utils
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
+// lockedSource 加锁的随机源，用于构建场景和生成每次渲染的基础种子
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+var source = &lockedSource{src: rand.NewSource(time.Now().UnixNano()).(rand.Source64)}
+var rng = rand.New(source)
+
+// Seed 设置全局随机数种子，相同种子下场景构建和渲染结果都可以复现，与渲染协程数无关
+func Seed(seed int64) {
+	source.Seed(seed)
+}
+
+// RenderSeed 从全局随机源取一次渲染的基础种子，各像素的种子由它和像素序号派生，见 PixelSeed
+func RenderSeed() int64 {
+	return rng.Int63()
+}
+
+// PixelSeed 由渲染的基础种子和像素序号得到该像素的种子，
+// 像素的随机序列因此与哪个协程、以什么顺序处理它无关
+func PixelSeed(base int64, index int) int64 {
+	return int64(splitMix64(uint64(base) + uint64(index)*0x9e3779b97f4a7c15))
+}
+
+// NewPixelRand 创建不加锁的随机数生成器，每个渲染协程各持有一个，
+// 处理每个像素前用 PixelSeed 重新播种；底层是 SplitMix64，播种几乎没有开销
+func NewPixelRand() *rand.Rand {
+	return rand.New(&splitMixSource{})
+}
+
+// splitMixSource SplitMix64随机源，状态只有一个整数
+type splitMixSource struct {
+	state uint64
+}
+
+func (s *splitMixSource) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *splitMixSource) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	return splitMix64(s.state)
+}
+
+func (s *splitMixSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// splitMix64 SplitMix64的输出混合函数
+func splitMix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
 func Random() float64 {
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-	return rand.Float64()
+	return rng.Float64()
+}
+
+// RandomFrom 从r中取[0,1)内的随机数，r为nil时使用全局随机源
+func RandomFrom(r *rand.Rand) float64 {
+	if r == nil {
+		return rng.Float64()
+	}
+	return r.Float64()
+}
+
+// RandomBetweenFrom 从r中取[min,max)内的随机数，r为nil时使用全局随机源
+func RandomBetweenFrom(r *rand.Rand, min, max float64) float64 {
+	return RandomFrom(r)*(max-min) + min
 }
 
 func RandomInt(min, max int) int {
-	rand.New(rand.NewSource(time.Now().UnixNano()))
-	return rand.Intn(max-min) + min
+	return rng.Intn(max-min) + min
 }
 
 func RandomBetween(min, max float64) float64 {
-	return rand.Float64()*(max-min) + min
+	return rng.Float64()*(max-min) + min
 }
 
 func Degrees2Radians(degrees float64) float64 {
@@ -1,16 +1,93 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
+type recordedProgress struct {
+	reports  []Progress
+	finishes []Progress
+}
+
+func (r *recordedProgress) Report(p Progress) { r.reports = append(r.reports, p) }
+func (r *recordedProgress) Finish(p Progress) { r.finishes = append(r.finishes, p) }
+
 func TestProcessBar(t *testing.T) {
-	total := int64(1000)
-	bar := NewProgressBar(total, 50)
+	var out bytes.Buffer
+	bar := NewProgressBar(&out, 10)
+	bar.Report(Progress{Total: 4})
+	bar.Report(Progress{Done: 1, Total: 4, Elapsed: 2 * time.Second})
+	bar.Finish(Progress{Done: 4, Total: 4, Elapsed: 8 * time.Second})
+	want := "\r[>         ]   0.00% (0/4) 0s/?" +
+		"\r[==>       ]  25.00% (1/4) 2s/6s" +
+		"\r[==========] 100.00% (4/4) 8s/0s\n"
+	if out.String() != want {
+		t.Errorf("got %q\nwant %q", out.String(), want)
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	recorded := &recordedProgress{}
+	total := int64(4000)
+	tracker := NewProgressTracker(total, recorded, time.Millisecond)
+	wg := sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int64(0); i < total/4; i++ {
+				tracker.Add(1)
+				if i%100 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	tracker.Finish()
+	tracker.Finish()
+
+	// 限流后汇报次数远少于任务数，且进度不倒退
+	if len(recorded.reports) < 2 || len(recorded.reports) > int(total)/10 {
+		t.Errorf("%d reports for %d tasks", len(recorded.reports), total)
+	}
+	for i := 1; i < len(recorded.reports); i++ {
+		if recorded.reports[i].Done < recorded.reports[i-1].Done {
+			t.Errorf("progress went backwards: %v", recorded.reports)
+			break
+		}
+	}
+	if len(recorded.finishes) != 1 || recorded.finishes[0].Done != total {
+		t.Errorf("finishes = %v", recorded.finishes)
+	}
+}
 
-	for i := int64(0); i <= total; i++ {
-		bar.Add(1)
-		time.Sleep(50 * time.Millisecond) // 模拟任务处理
+func TestJSONProgress(t *testing.T) {
+	var out bytes.Buffer
+	reporter := NewJSONProgress(&out)
+	reporter.Report(Progress{Total: 10})
+	reporter.Report(Progress{Done: 5, Total: 10, Elapsed: 1500 * time.Millisecond})
+	reporter.Finish(Progress{Done: 10, Total: 10, Elapsed: 3 * time.Second})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), out.String())
+	}
+	if lines[0] != `{"done":0,"total":10,"percent":0,"elapsed":0,"finished":false}` {
+		t.Errorf("line 0 = %s", lines[0])
+	}
+	var line progressJSON
+	if err := json.Unmarshal([]byte(lines[1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.Percent != 50 || line.Elapsed != 1.5 || line.ETA == nil || *line.ETA != 1.5 || line.Finished {
+		t.Errorf("line 1 = %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"finished":true`) || reporter.Err() != nil {
+		t.Errorf("line 2 = %s, err %v", lines[2], reporter.Err())
 	}
 }